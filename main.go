@@ -53,7 +53,7 @@ var rootCmd = &cobra.Command{
 	Use:   "ov",
 	Short: "ov is a feature rich pager",
 	Long: `ov is a feature rich pager(such as more/less).
-It supports various compressed files(gzip, bzip2, zstd, lz4, and xz).
+It supports various compressed files(gzip, bzip2, zstd, lz4, xz, brotli, zlib, lzip, and snappy).
 `,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -78,6 +78,17 @@ It supports various compressed files(gzip, bzip2, zstd, lz4, and xz).
 		if completion != "" {
 			return Completion(cmd, completion)
 		}
+		if oviewer.CompressFormat != "" {
+			if _, err := oviewer.ParseCompressed(oviewer.CompressFormat); err != nil {
+				return err
+			}
+		}
+		if _, err := oviewer.ParseEncoding(oviewer.Encoding); err != nil {
+			return err
+		}
+		if _, _, err := oviewer.ParseRecordSeparator(oviewer.RecordSeparator); err != nil {
+			return err
+		}
 		// Actually tabs when "\t" is specified as an option.
 		if config.General.ColumnDelimiter == "\\t" {
 			config.General.ColumnDelimiter = "\t"
@@ -88,6 +99,19 @@ It supports various compressed files(gzip, bzip2, zstd, lz4, and xz).
 		oviewer.OverLineStyle = oviewer.ToTcellStyle(config.StyleOverLine)
 		oviewer.MemoryLimit = config.MemoryLimit
 		oviewer.MemoryLimitFile = config.MemoryLimitFile
+		budget, err := oviewer.ParseMemorySize(config.MemoryBudget)
+		if err != nil {
+			return err
+		}
+		oviewer.MemoryBudget = budget
+		lineLimit, err := oviewer.ParseMemorySize(config.LineLimit)
+		if err != nil {
+			return err
+		}
+		oviewer.LineLimit = int(lineLimit)
+		oviewer.IndexCache = config.IndexCache
+		oviewer.Mmap = config.Mmap
+		oviewer.WatchHistory = config.WatchHistory
 		SetRedirect()
 
 		if execCommand {
@@ -122,7 +146,13 @@ func Completion(cmd *cobra.Command, arg string) error {
 
 // RunOviewer displays the argument file.
 func RunOviewer(args []string) error {
-	ov, err := oviewer.Open(args...)
+	var ov *oviewer.Root
+	var err error
+	if len(config.FollowGlob) > 0 {
+		ov, err = oviewer.OpenGlob(append(args, config.FollowGlob...)...)
+	} else {
+		ov, err = oviewer.Open(args...)
+	}
 	if err != nil {
 		return err
 	}
@@ -152,6 +182,10 @@ func ExecCommand(args []string) error {
 		return ErrNoArgument
 	}
 	cmd := oviewer.NewCommand(args...)
+	cmd.StopOnError = config.StopOnError
+	cmd.Pty = config.Pty
+	cmd.Combined = config.Combined
+	cmd.Stdin = config.ExecStdin
 	ov, err := cmd.Exec()
 	if err != nil {
 		return err
@@ -308,6 +342,18 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&pattern, "pattern", "", "", "search pattern")
 
 	rootCmd.PersistentFlags().BoolVarP(&oviewer.SkipExtract, "skip-extract", "", false, "skip extract")
+	rootCmd.PersistentFlags().StringVarP(&oviewer.CompressFormat, "compress-format", "", "", "compression format of the file instead of detecting it")
+	_ = rootCmd.RegisterFlagCompletionFunc("compress-format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"gzip", "bzip2", "zstd", "lz4", "xz", "brotli", "zlib", "lzip", "snappy", "none"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().StringVarP(&oviewer.Encoding, "encoding", "", "", "character encoding of the file instead of detecting it")
+	_ = rootCmd.RegisterFlagCompletionFunc("encoding", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return oviewer.EncodingNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().StringVarP(&oviewer.RecordSeparator, "record-separator", "", "", "record separator(lf, crlf, cr, nul or a character)")
+	_ = rootCmd.RegisterFlagCompletionFunc("record-separator", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"lf", "crlf", "cr", "nul"}, cobra.ShellCompDirectiveNoFileComp
+	})
 
 	// Config.General
 	rootCmd.PersistentFlags().IntP("tab-width", "x", 8, "tab stop width")
@@ -322,6 +368,12 @@ func init() {
 		return []string{"1"}, cobra.ShellCompDirectiveNoFileComp
 	})
 
+	rootCmd.PersistentFlags().IntP("header-column", "", 0, "number of columns to be displayed constantly at the left edge")
+	_ = viper.BindPFlag("general.HeaderColumn", rootCmd.PersistentFlags().Lookup("header-column"))
+	_ = rootCmd.RegisterFlagCompletionFunc("header-column", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"1"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
 	rootCmd.PersistentFlags().IntP("skip-lines", "", 0, "skip the number of lines")
 	_ = viper.BindPFlag("general.SkipLines", rootCmd.PersistentFlags().Lookup("skip-lines"))
 	_ = rootCmd.RegisterFlagCompletionFunc("skip-lines", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -340,9 +392,15 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("column-rainbow", "", false, "column mode to rainbow")
 	_ = viper.BindPFlag("general.ColumnRainbow", rootCmd.PersistentFlags().Lookup("column-rainbow"))
 
+	rootCmd.PersistentFlags().BoolP("column-name", "", false, "display the column name of the cursor")
+	_ = viper.BindPFlag("general.ColumnName", rootCmd.PersistentFlags().Lookup("column-name"))
+
 	rootCmd.PersistentFlags().BoolP("line-number", "n", false, "line number mode")
 	_ = viper.BindPFlag("general.LineNumMode", rootCmd.PersistentFlags().Lookup("line-number"))
 
+	rootCmd.PersistentFlags().BoolP("time-delta", "", false, "display the relative time from the previous line")
+	_ = viper.BindPFlag("general.TimeDelta", rootCmd.PersistentFlags().Lookup("time-delta"))
+
 	rootCmd.PersistentFlags().BoolP("wrap", "w", true, "wrap mode")
 	_ = viper.BindPFlag("general.WrapMode", rootCmd.PersistentFlags().Lookup("wrap"))
 
@@ -373,9 +431,45 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("follow-name", "", false, "file name follow mode")
 	_ = viper.BindPFlag("general.FollowName", rootCmd.PersistentFlags().Lookup("follow-name"))
 
+	rootCmd.PersistentFlags().BoolP("keep-rotated", "", false, "keep the content of the rotated file in follow mode")
+	_ = viper.BindPFlag("general.KeepRotated", rootCmd.PersistentFlags().Lookup("keep-rotated"))
+
+	rootCmd.PersistentFlags().StringSliceP("follow-glob", "", nil, "follow all files matching the pattern, opening new files as they appear")
+	_ = viper.BindPFlag("FollowGlob", rootCmd.PersistentFlags().Lookup("follow-glob"))
+
+	rootCmd.PersistentFlags().BoolP("close-removed", "", false, "close the documents of removed files in follow glob")
+	_ = viper.BindPFlag("CloseRemoved", rootCmd.PersistentFlags().Lookup("close-removed"))
+
+	rootCmd.PersistentFlags().BoolP("merge", "", false, "display the document that merges all files by timestamp")
+	_ = viper.BindPFlag("Merge", rootCmd.PersistentFlags().Lookup("merge"))
+
+	rootCmd.PersistentFlags().StringP("merge-time-regexp", "", "", "regular expression that matches the timestamp to merge")
+	_ = viper.BindPFlag("MergeTimeRegexp", rootCmd.PersistentFlags().Lookup("merge-time-regexp"))
+
+	rootCmd.PersistentFlags().StringP("merge-time-format", "", "", "layout of the timestamp to merge (e.g. \"2006-01-02 15:04:05\")")
+	_ = viper.BindPFlag("MergeTimeFormat", rootCmd.PersistentFlags().Lookup("merge-time-format"))
+
 	rootCmd.PersistentFlags().IntP("watch", "T", 0, "watch mode interval(`seconds`)")
 	_ = viper.BindPFlag("general.WatchInterval", rootCmd.PersistentFlags().Lookup("watch"))
 
+	rootCmd.PersistentFlags().BoolP("watch-diff", "", false, "highlight the changes from the previous watch refresh")
+	_ = viper.BindPFlag("general.WatchDiff", rootCmd.PersistentFlags().Lookup("watch-diff"))
+
+	rootCmd.PersistentFlags().IntP("watch-history", "", 10, "number of watch snapshots to keep (0 is unlimited)")
+	_ = viper.BindPFlag("WatchHistory", rootCmd.PersistentFlags().Lookup("watch-history"))
+
+	rootCmd.PersistentFlags().BoolP("stop-on-error", "", false, "stop watching when the command exits with a non-zero status")
+	_ = viper.BindPFlag("StopOnError", rootCmd.PersistentFlags().Lookup("stop-on-error"))
+
+	rootCmd.PersistentFlags().BoolP("pty", "", false, "run the command of exec under a pty (linux only)")
+	_ = viper.BindPFlag("Pty", rootCmd.PersistentFlags().Lookup("pty"))
+
+	rootCmd.PersistentFlags().BoolP("combined", "", false, "add the document that interleaves stdout and stderr of exec")
+	_ = viper.BindPFlag("Combined", rootCmd.PersistentFlags().Lookup("combined"))
+
+	rootCmd.PersistentFlags().BoolP("exec-stdin", "", false, "write lines to the stdin of the command of exec")
+	_ = viper.BindPFlag("ExecStdin", rootCmd.PersistentFlags().Lookup("exec-stdin"))
+
 	rootCmd.PersistentFlags().StringSliceP("multi-color", "M", nil, "comma separated words(regexp) to color .e.g. \"ERROR,WARNING\"")
 	_ = viper.BindPFlag("general.MultiColorWords", rootCmd.PersistentFlags().Lookup("multi-color"))
 
@@ -419,6 +513,18 @@ func init() {
 	rootCmd.PersistentFlags().IntP("memory-limit-file", "", 100, "number of chunks to limit in memory for the file")
 	_ = viper.BindPFlag("MemoryLimitFile", rootCmd.PersistentFlags().Lookup("memory-limit-file"))
 
+	rootCmd.PersistentFlags().StringP("memory-budget", "", "", "size of memory for lines of all documents (e.g. 512M)")
+	_ = viper.BindPFlag("MemoryBudget", rootCmd.PersistentFlags().Lookup("memory-budget"))
+
+	rootCmd.PersistentFlags().StringP("line-limit", "", "4M", "size of a line to display (e.g. 4M), 0 is unlimited")
+	_ = viper.BindPFlag("LineLimit", rootCmd.PersistentFlags().Lookup("line-limit"))
+
+	rootCmd.PersistentFlags().BoolP("index-cache", "", false, "save the line index of large files in the cache directory")
+	_ = viper.BindPFlag("IndexCache", rootCmd.PersistentFlags().Lookup("index-cache"))
+
+	rootCmd.PersistentFlags().BoolP("mmap", "", false, "map regular files into memory instead of reading chunks")
+	_ = viper.BindPFlag("Mmap", rootCmd.PersistentFlags().Lookup("mmap"))
+
 	rootCmd.PersistentFlags().BoolP("disable-column-cycle", "", false, "disable column cycling")
 	_ = viper.BindPFlag("DisableColumnCycle", rootCmd.PersistentFlags().Lookup("disable-column-cycle"))
 
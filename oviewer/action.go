@@ -1,6 +1,7 @@
 package oviewer
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -65,6 +66,13 @@ func (root *Root) toggleLineNumMode() {
 	root.setMessagef("Set LineNumMode %t", root.Doc.LineNumMode)
 }
 
+// toggleTimeDelta toggles the relative time display every time it is called.
+func (root *Root) toggleTimeDelta() {
+	root.Doc.TimeDelta = !root.Doc.TimeDelta
+	root.ViewSync()
+	root.setMessagef("Set TimeDelta %t", root.Doc.TimeDelta)
+}
+
 // togglePlain toggles plain mode.
 func (root *Root) togglePlain() {
 	root.Doc.PlainMode = !root.Doc.PlainMode
@@ -77,6 +85,12 @@ func (root *Root) toggleRainbow() {
 	root.setMessagef("Set Column Rainbow Mode %t", root.Doc.ColumnRainbow)
 }
 
+// toggleColumnName toggles the display of the column name.
+func (root *Root) toggleColumnName() {
+	root.Doc.ColumnName = !root.Doc.ColumnName
+	root.setMessagef("Set Column Name %t", root.Doc.ColumnName)
+}
+
 // toggleFollowMode toggles follow mode.
 func (root *Root) toggleFollowMode() {
 	root.Doc.FollowMode = !root.Doc.FollowMode
@@ -117,6 +131,10 @@ func (root *Root) closeFile() {
 
 // reload performs a reload of the current document.
 func (root *Root) reload(m *Document) {
+	// The combined document is updated by rerunning the command.
+	if m.combined != nil && m.command != nil {
+		m = m.command.docout
+	}
 	if err := m.reload(); err != nil {
 		root.setMessageLogf("cannot reload: %s", err)
 		return
@@ -126,6 +144,17 @@ func (root *Root) reload(m *Document) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+// rerun restarts the command of the exec document.
+func (root *Root) rerun() {
+	m := root.Doc
+	if m.command == nil {
+		root.setMessagef("rerun: %s", ErrNotCommand)
+		return
+	}
+	root.reload(m.command.docout)
+	root.setMessagef("rerun %s", strings.Join(m.command.args, " "))
+}
+
 // toggleWatch toggles watch mode.
 func (root *Root) toggleWatch() {
 	if root.Doc.WatchMode {
@@ -160,6 +189,10 @@ func (root *Root) watchControl() {
 				m.tickerDone <- struct{}{}
 				return
 			case <-m.ticker.C:
+				// Watch mode may be stopped by the command that exits with an error.
+				if !m.WatchMode {
+					continue
+				}
 				ev := &eventReload{}
 				ev.SetEventNow()
 				ev.m = m
@@ -190,10 +223,18 @@ func (root *Root) searchGo(lN int) {
 // .5 -> 50% of the way down the file
 // decimal + "%" is a percentage position
 // 50% -> 50% of the way down the file
-func (root *Root) goLine(input string) {
+func (root *Root) goLine(ctx context.Context, input string) {
 	if len(input) == 0 {
 		return
 	}
+	if root.Doc.hex != nil && isOffset(input) {
+		root.goOffset(input)
+		return
+	}
+	if isTimeInput(input) {
+		root.goTime(ctx, input)
+		return
+	}
 	num := docPosition(root.Doc.BufEndNum(), input)
 	str := strconv.FormatFloat(num, 'f', 1, 64)
 	if strings.HasSuffix(str, ".0") {
@@ -275,6 +316,9 @@ func (root *Root) removeMark() {
 		return
 	}
 	root.Doc.marked = marked
+	if root.Doc.deleteBookmarkLine(c) {
+		root.Doc.saveBookmarks()
+	}
 	root.setMessagef("Remove the mark at line %d", c-root.Doc.firstLine()+1)
 }
 
@@ -282,6 +326,10 @@ func (root *Root) removeMark() {
 func (root *Root) removeAllMark() {
 	root.Doc.marked = nil
 	root.Doc.markedPoint = 0
+	if len(root.Doc.bookmarks) > 0 {
+		root.Doc.bookmarks = nil
+		root.Doc.saveBookmarks()
+	}
 	root.setMessage("Remove all marks")
 }
 
@@ -527,6 +575,9 @@ func (root *Root) setJumpTarget(input string) {
 // resize is a wrapper function that calls viewSync.
 func (root *Root) resize() {
 	root.ViewSync()
+	if c := root.Doc.command; c != nil && c.Pty {
+		c.resize(root.scr.vWidth, root.scr.vHeight-statusLine)
+	}
 }
 
 // jumpPosition determines the position of the jump.
@@ -612,6 +663,10 @@ func (root *Root) prepareStartX() {
 	if root.Doc.LineNumMode {
 		root.scr.startX = len(fmt.Sprintf("%d", root.Doc.BufEndNum())) + 1
 	}
+	root.scr.timeX = root.scr.startX
+	if root.Doc.TimeDelta {
+		root.scr.startX += timeDeltaWidth
+	}
 }
 
 // updateEndNum updates the last line number.
@@ -647,6 +702,10 @@ func (root *Root) followAll() {
 	if root.screenMode != Docs {
 		return
 	}
+	// The merged and combined documents display the updates of all documents.
+	if root.Doc.merge != nil || root.Doc.combined != nil {
+		return
+	}
 
 	current := root.CurrentDoc
 	root.mu.RLock()
@@ -0,0 +1,316 @@
+package oviewer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// archiveFormat represents the type of archive.
+type archiveFormat int
+
+const (
+	// notArchive is not an archive.
+	notArchive archiveFormat = iota
+	// tarArchive is a tar archive (may be compressed).
+	tarArchive
+	// zipArchive is a zip archive.
+	zipArchive
+)
+
+// sniffSize is the size of the beginning of the data used to detect the type.
+const sniffSize = 512
+
+// archiveEagerMembers is the number of members read when the archive is opened.
+// The other members are read when they are displayed.
+const archiveEagerMembers = 8
+
+// archiveFile is the archive file shared by the member documents.
+// It is closed when the last member document is closed.
+type archiveFile struct {
+	file *os.File
+	refs int32
+}
+
+// release closes the archive file if no member document refers to it.
+func (a *archiveFile) release() {
+	if atomic.AddInt32(&a.refs, -1) != 0 {
+		return
+	}
+	if err := a.file.Close(); err != nil {
+		log.Printf("archive: %s", err)
+	}
+}
+
+// archiveType returns the type of archive from the beginning of the uncompressed data.
+func archiveType(header []byte) archiveFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return zipArchive
+	case len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar")):
+		return tarArchive
+	}
+	return notArchive
+}
+
+// isBinary returns true if the data looks like binary.
+// Like grep and git, data containing a NUL byte is regarded as binary.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), sniffSize)], 0) >= 0
+}
+
+// fileArchiveType returns the type of archive of the file.
+// Compressed tar files (such as .tar.gz) are also detected.
+func fileArchiveType(fileName string) archiveFormat {
+	// Named pipes are not read here, because the contents are consumed.
+	fi, err := os.Stat(fileName)
+	if err != nil || !fi.Mode().IsRegular() {
+		return notArchive
+	}
+	f, err := os.Open(fileName)
+	if err != nil {
+		return notArchive
+	}
+	defer f.Close()
+	_, r := uncompressedReader(f, fileName, false)
+	header := make([]byte, sniffSize)
+	n, _ := io.ReadFull(r, header)
+	return archiveType(header[:n])
+}
+
+// openDocuments opens a file and returns documents.
+// If the file is an archive, a document is returned for each text member.
+func openDocuments(fileName string) ([]*Document, error) {
+	if !SkipExtract {
+		if format := fileArchiveType(fileName); format != notArchive {
+			return openArchive(fileName, format)
+		}
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return []*Document{m}, nil
+}
+
+// OpenArchive opens an archive (tar, compressed tar, zip)
+// and returns a Document for each text member.
+// Compressed members are decompressed, and binary members are skipped.
+func OpenArchive(fileName string) ([]*Document, error) {
+	return openArchive(fileName, fileArchiveType(fileName))
+}
+
+// openArchive opens an archive of the format.
+// The members of zip and uncompressed tar archives are read lazily
+// by each document, so the archive file is kept open until all of them are closed.
+// Only the first archiveEagerMembers members start reading,
+// and the others start when they are displayed.
+func openArchive(fileName string, format archiveFormat) ([]*Document, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*Document
+	add := func(name string, r io.Reader) error {
+		m, err := archiveDocument(fileName, name, r, len(docs) >= archiveEagerMembers)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			log.Printf("%s:%s: skip binary", fileName, name)
+			return nil
+		}
+		docs = append(docs, m)
+		return nil
+	}
+
+	lazy := false
+	switch format {
+	case tarArchive:
+		lazy, err = readTarMembers(f, fileName, add)
+	case zipArchive:
+		lazy, err = true, readZipMembers(f, add)
+	default:
+		err = ErrNotArchive
+	}
+	if err == nil && len(docs) == 0 {
+		err = ErrNoTextMember
+	}
+	if err != nil || !lazy {
+		f.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	if lazy {
+		archive := &archiveFile{file: f, refs: int32(len(docs))}
+		for _, m := range docs {
+			m.archive = archive
+		}
+	}
+	return docs, nil
+}
+
+// readTarMembers calls fn for each regular file in the tar archive.
+// The members of an uncompressed tar are read directly from the file later, and lazy is true.
+// The members of a compressed tar are read into memory, because it is read sequentially.
+// The total size is limited to MemoryBudget if it is set.
+func readTarMembers(f *os.File, fileName string, fn func(name string, r io.Reader) error) (lazy bool, err error) {
+	cFormat, r := uncompressedReader(f, fileName, true)
+	lazy = cFormat == UNCOMPRESSED
+	if lazy {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		r = f
+	}
+	budget := MemoryBudget
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return lazy, nil
+			}
+			return lazy, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		var member io.Reader
+		if lazy && !isSparse(hdr) {
+			// The data of the member follows the header.
+			pos, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return lazy, err
+			}
+			member = io.NewSectionReader(f, pos, hdr.Size)
+		} else {
+			data, err := readTarMember(tr, hdr, &budget)
+			if err != nil {
+				return lazy, err
+			}
+			member = bytes.NewReader(data)
+		}
+		if err := fn(hdr.Name, member); err != nil {
+			return lazy, err
+		}
+	}
+}
+
+// isSparse returns true if the member is a sparse file,
+// whose data is not stored continuously in the archive.
+func isSparse(hdr *tar.Header) bool {
+	for k := range hdr.PAXRecords {
+		if strings.HasPrefix(k, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// readTarMember reads the member into memory within the budget.
+// A negative or zero budget means unlimited.
+func readTarMember(tr *tar.Reader, hdr *tar.Header, budget *int64) ([]byte, error) {
+	if MemoryBudget <= 0 {
+		return io.ReadAll(tr)
+	}
+	limit := max(*budget, 0)
+	data, err := io.ReadAll(io.LimitReader(tr, limit))
+	if err != nil {
+		return nil, err
+	}
+	*budget -= int64(len(data))
+	if int64(len(data)) < hdr.Size {
+		log.Printf("%s: truncated at %d bytes by the memory budget", hdr.Name, len(data))
+	}
+	return data, nil
+}
+
+// readZipMembers calls fn for each file in the zip archive.
+// The members are read from the file later.
+func readZipMembers(f *os.File, fn func(name string, r io.Reader) error) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		return err
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		if err := fn(zf.Name, rc); err != nil {
+			rc.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveDocument returns a Document of the archive member.
+// Only the beginning of the member is read here to check if it is binary,
+// and the rest is read by the document.
+// If deferred is true, the document starts reading when it is displayed.
+// It returns nil if the member is binary.
+func archiveDocument(fileName string, name string, r io.Reader, deferred bool) (*Document, error) {
+	cFormat, ur := uncompressedReader(r, name, false)
+	enc, dr := decodedReader(ur, Encoding)
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(dr, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	head = head[:n]
+	if isBinary(head) {
+		return nil, nil
+	}
+
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	m.FileName = fileName + ":" + name
+	m.CFormat = cFormat
+	m.Encoding = enc
+	m.reopenable = false
+	m.preventReload = true
+	m.archived = true
+	if deferred {
+		m.deferred = 1
+	}
+	if err := m.ControlReader(io.MultiReader(bytes.NewReader(head), dr), nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// startDeferred starts reading the document whose reading is deferred.
+func (m *Document) startDeferred() {
+	if atomic.CompareAndSwapInt32(&m.deferred, 1, 0) {
+		m.requestStart()
+	}
+}
+
+// releaseArchive releases the archive file of the member document.
+func (m *Document) releaseArchive() {
+	if m.archive == nil {
+		return
+	}
+	m.archive.release()
+	m.archive = nil
+}
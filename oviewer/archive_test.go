@@ -0,0 +1,259 @@
+package oviewer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type archiveTestMember struct {
+	name string
+	data []byte
+}
+
+func archiveTestMembers(t *testing.T) []archiveTestMember {
+	t.Helper()
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	if _, err := w.Write([]byte("nested 1\nnested 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return []archiveTestMember{
+		{name: "bundle/a.log", data: []byte("a 1\na 2\na 3\n")},
+		{name: "bundle/core.bin", data: []byte{0x7f, 'E', 'L', 'F', 0, 0, 1}},
+		{name: "bundle/b.log.gz", data: gz.Bytes()},
+	}
+}
+
+func writeTarTestFile(t *testing.T, fileName string, members []archiveTestMember, compress bool) {
+	t.Helper()
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	if err := tw.WriteHeader(&tar.Header{Name: "bundle/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		if err := tw.WriteHeader(&tar.Header{Name: m.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(m.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+	if compress {
+		var gz bytes.Buffer
+		w := gzip.NewWriter(&gz)
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = gz.Bytes()
+	}
+	if err := os.WriteFile(fileName, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZipTestFile(t *testing.T, fileName string, members []archiveTestMember) {
+	t.Helper()
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	if _, err := zw.Create("bundle/"); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range members {
+		w, err := zw.Create(m.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, b.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	members := archiveTestMembers(t)
+	tarFile := filepath.Join(dir, "bundle.tar")
+	writeTarTestFile(t, tarFile, members, false)
+	tgzFile := filepath.Join(dir, "bundle.tar.gz")
+	writeTarTestFile(t, tgzFile, members, true)
+	zipFile := filepath.Join(dir, "bundle.zip")
+	writeZipTestFile(t, zipFile, members)
+
+	tests := []struct {
+		name     string
+		fileName string
+		want     archiveFormat
+	}{
+		{name: "tar", fileName: tarFile, want: tarArchive},
+		{name: "tar.gz", fileName: tgzFile, want: tarArchive},
+		{name: "zip", fileName: zipFile, want: zipArchive},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := fileArchiveType(tt.fileName); got != tt.want {
+				t.Fatalf("fileArchiveType() = %v, want %v", got, tt.want)
+			}
+			docs, err := OpenArchive(tt.fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantDocs := []struct {
+				fileName string
+				cFormat  Compressed
+				lines    []string
+			}{
+				{fileName: tt.fileName + ":bundle/a.log", cFormat: UNCOMPRESSED, lines: []string{"a 1", "a 2", "a 3"}},
+				{fileName: tt.fileName + ":bundle/b.log.gz", cFormat: GZIP, lines: []string{"nested 1", "nested 2"}},
+			}
+			if len(docs) != len(wantDocs) {
+				t.Fatalf("OpenArchive() = %d documents, want %d", len(docs), len(wantDocs))
+			}
+			for i, want := range wantDocs {
+				m := docs[i]
+				for !m.BufEOF() {
+				}
+				if m.FileName != want.fileName {
+					t.Errorf("FileName = %s, want %s", m.FileName, want.fileName)
+				}
+				if m.CFormat != want.cFormat {
+					t.Errorf("CFormat = %s, want %s", m.CFormat, want.cFormat)
+				}
+				if got := m.BufEndNum(); got != len(want.lines) {
+					t.Fatalf("BufEndNum() = %d, want %d", got, len(want.lines))
+				}
+				for lN, line := range want.lines {
+					if got := m.LineString(lN); got != line {
+						t.Errorf("LineString(%d) = %q, want %q", lN, got, line)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOpenArchive_deferred(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "many.tar")
+	var members []archiveTestMember
+	for i := 0; i <= archiveEagerMembers; i++ {
+		members = append(members, archiveTestMember{name: fmt.Sprintf("%d.log", i), data: []byte("line\n")})
+	}
+	writeTarTestFile(t, fileName, members, false)
+	docs, err := OpenArchive(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := docs[archiveEagerMembers]
+	for _, m := range docs[:archiveEagerMembers] {
+		for !m.BufEOF() {
+		}
+	}
+	if last.BufEOF() || last.BufEndNum() != 0 {
+		t.Fatalf("the member after %d is read before displayed", archiveEagerMembers)
+	}
+	last.startDeferred()
+	for !last.BufEOF() {
+	}
+	if got := last.LineString(0); got != "line" {
+		t.Errorf("LineString(0) = %q, want %q", got, "line")
+	}
+
+	// The archive file is closed when the last member is closed.
+	archive := last.archive
+	for _, m := range docs {
+		if _, err := archive.file.Stat(); err != nil {
+			t.Fatalf("closed before all members are closed: %s", err)
+		}
+		m.requestClose()
+	}
+	if _, err := archive.file.Stat(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Stat() error = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestOpenArchive_error(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	binFile := filepath.Join(dir, "bin.tar")
+	writeTarTestFile(t, binFile, archiveTestMembers(t)[1:2], false)
+	if _, err := OpenArchive(binFile); !errors.Is(err, ErrNoTextMember) {
+		t.Errorf("OpenArchive() error = %v, want %v", err, ErrNoTextMember)
+	}
+	if _, err := OpenArchive(filepath.Join("..", "testdata", "normal.txt")); !errors.Is(err, ErrNotArchive) {
+		t.Errorf("OpenArchive() error = %v, want %v", err, ErrNotArchive)
+	}
+}
+
+func Test_openDocuments(t *testing.T) {
+	t.Parallel()
+	docs, err := openDocuments(filepath.Join("..", "testdata", "normal.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 {
+		t.Errorf("openDocuments() = %d documents, want 1", len(docs))
+	}
+}
+
+func Test_readTarMember(t *testing.T) {
+	defer func(budget int64) { MemoryBudget = budget }(MemoryBudget)
+	MemoryBudget = 20
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, name := range []string{"a.log", "b.log"} {
+		data := []byte("0123456789abcdef\n")
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	budget := MemoryBudget
+	tr := tar.NewReader(&b)
+	// The second member is truncated by the rest of the budget.
+	for _, want := range []int{17, 3} {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := readTarMember(tr, hdr, &budget)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != want {
+			t.Errorf("readTarMember(%s) = %d bytes, want %d", hdr.Name, len(data), want)
+		}
+	}
+}
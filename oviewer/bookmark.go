@@ -0,0 +1,325 @@
+package oviewer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// bookmarkVersion is the version of the bookmark file format.
+const bookmarkVersion = 1
+
+// bookmark is a named mark of the line with an optional note.
+type bookmark struct {
+	// Name is the name of the bookmark (e.g. "a").
+	Name string
+	// Note is the optional note of the bookmark.
+	Note string
+	// LN is the line number of the document.
+	LN int
+}
+
+// bookmarkFile is the bookmarks of a file saved in the state directory.
+type bookmarkFile struct {
+	// FileName is the absolute path of the file.
+	FileName string
+	// Bookmarks is the bookmarks of the file.
+	Bookmarks []bookmark
+	// Size is the size of the file when the bookmarks were saved.
+	Size int64
+	// Inode is the inode number of the file (0 if not supported).
+	Inode uint64
+	// Version is the version of the bookmark file format.
+	Version int
+}
+
+// stateDir returns the directory to save the state.
+// $XDG_STATE_HOME is used if set, otherwise ~/.local/state (the config directory on windows).
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return os.UserConfigDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// bookmarkPath returns the path of the bookmark file of the file.
+func bookmarkPath(fileName string) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(fileName))
+	return filepath.Join(dir, "ov", "bookmarks", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// readBookmarks reads the bookmark file of the file.
+func readBookmarks(fileName string) (*bookmarkFile, error) {
+	path, err := bookmarkPath(fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	bf := &bookmarkFile{}
+	if err := json.Unmarshal(data, bf); err != nil {
+		return nil, err
+	}
+	if bf.Version != bookmarkVersion || bf.FileName != fileName {
+		return nil, ErrInvalidBookmark
+	}
+	return bf, nil
+}
+
+// writeBookmarks writes the bookmark file.
+// The file is removed if there are no bookmarks.
+func writeBookmarks(bf *bookmarkFile) error {
+	path, err := bookmarkPath(bf.FileName)
+	if err != nil {
+		return err
+	}
+	if len(bf.Bookmarks) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(bf)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it so that a broken file is not left.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "bookmark")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadBookmarks restores the bookmarks saved for the same file.
+// The bookmarks are not restored if the file has been replaced or truncated.
+func (m *Document) loadBookmarks() {
+	if m.filepath == "" {
+		return
+	}
+	bf, err := readBookmarks(m.filepath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("bookmark: %s", err)
+		}
+		return
+	}
+	fi, err := os.Stat(m.filepath)
+	if err != nil {
+		return
+	}
+	if bf.Inode != fileInode(fi) || fi.Size() < bf.Size {
+		return
+	}
+	m.bookmarks = bf.Bookmarks
+	for _, b := range m.bookmarks {
+		m.marked = remove(m.marked, b.LN)
+		m.marked = append(m.marked, b.LN)
+	}
+}
+
+// saveBookmarks saves the bookmarks of the file.
+func (m *Document) saveBookmarks() {
+	if m.filepath == "" {
+		return
+	}
+	fi, err := os.Stat(m.filepath)
+	if err != nil {
+		return
+	}
+	bf := &bookmarkFile{
+		Version:   bookmarkVersion,
+		FileName:  m.filepath,
+		Size:      fi.Size(),
+		Inode:     fileInode(fi),
+		Bookmarks: m.bookmarks,
+	}
+	if err := writeBookmarks(bf); err != nil {
+		log.Printf("bookmark: %s", err)
+	}
+}
+
+// parseBookmark parses the input of "name note".
+func parseBookmark(input string) (string, string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", "", fmt.Errorf("%w: empty name", ErrInvalidBookmark)
+	}
+	name, note, _ := strings.Cut(input, " ")
+	return name, strings.TrimSpace(note), nil
+}
+
+// setBookmark sets the bookmark of the name to the line.
+// The bookmark of the same name is moved.
+func (m *Document) setBookmark(name string, note string, lN int) {
+	m.deleteBookmark(name)
+	m.bookmarks = append(m.bookmarks, bookmark{Name: name, Note: note, LN: lN})
+	sort.SliceStable(m.bookmarks, func(i, j int) bool {
+		return m.bookmarks[i].Name < m.bookmarks[j].Name
+	})
+	m.marked = remove(m.marked, lN)
+	m.marked = append(m.marked, lN)
+}
+
+// deleteBookmark deletes the bookmark of the name.
+func (m *Document) deleteBookmark(name string) bool {
+	for i, b := range m.bookmarks {
+		if b.Name == name {
+			m.bookmarks = append(m.bookmarks[:i:i], m.bookmarks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// deleteBookmarkLine deletes the bookmarks of the line.
+func (m *Document) deleteBookmarkLine(lN int) bool {
+	bookmarks := make([]bookmark, 0, len(m.bookmarks))
+	for _, b := range m.bookmarks {
+		if b.LN != lN {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	deleted := len(bookmarks) != len(m.bookmarks)
+	m.bookmarks = bookmarks
+	return deleted
+}
+
+// findBookmark returns the bookmark of the name.
+func (m *Document) findBookmark(name string) (bookmark, bool) {
+	for _, b := range m.bookmarks {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return bookmark{}, false
+}
+
+// bookmarkList returns the bookmarks and the marks as a list of "file:line: text".
+// The named bookmarks are followed by the marks in order of lines.
+// The note is displayed instead of the line if the bookmark has a note.
+func (m *Document) bookmarkList() ([]byte, []int) {
+	fileName := m.filepath
+	if fileName == "" {
+		fileName = m.FileName
+	}
+	var buf bytes.Buffer
+	var lineNumMap []int
+	named := make(map[int]bool)
+	for _, b := range m.bookmarks {
+		text := b.Note
+		if text == "" {
+			text = m.LineString(b.LN)
+		}
+		fmt.Fprintf(&buf, "%s:%d: '%s %s\n", fileName, b.LN+1, b.Name, text)
+		lineNumMap = append(lineNumMap, b.LN)
+		named[b.LN] = true
+	}
+	marked := append([]int(nil), m.marked...)
+	sort.Ints(marked)
+	for _, lN := range marked {
+		if named[lN] {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s:%d: %s\n", fileName, lN+1, m.LineString(lN))
+		lineNumMap = append(lineNumMap, lN)
+	}
+	return buf.Bytes(), lineNumMap
+}
+
+// bookmarkDocument returns a new document that lists the bookmarks and the marks.
+func (m *Document) bookmarkDocument() (*Document, error) {
+	list, lineNumMap := m.bookmarkList()
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = m.FileName
+	doc.Caption = "(bookmarks)" + m.FileName
+	doc.parent = m
+	doc.lineNumMap = lineNumMap
+	doc.preventReload = true
+	if err := doc.ControlReader(bytes.NewReader(list), nil); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// setBookmarkCurrent sets the bookmark to the current line.
+func (root *Root) setBookmarkCurrent(input string) {
+	m := root.Doc
+	name, note, err := parseBookmark(input)
+	if err != nil {
+		root.setMessagef("bookmark: %s", err)
+		return
+	}
+	lN := min(m.topLN+m.firstLine(), m.BufEndNum())
+	m.setBookmark(name, note, lN)
+	m.saveBookmarks()
+	root.setMessagef("Bookmark '%s at line %d", name, lN-m.firstLine()+1)
+}
+
+// goBookmark moves to the line of the bookmark.
+func (root *Root) goBookmark(input string) {
+	name := strings.TrimSpace(input)
+	b, ok := root.Doc.findBookmark(name)
+	if !ok {
+		root.setMessagef("bookmark: no bookmark '%s", name)
+		return
+	}
+	root.goLineNumber(b.LN)
+	if b.Note != "" {
+		root.setMessagef("'%s %s", b.Name, b.Note)
+	}
+}
+
+// listBookmarks adds a document that lists the bookmarks and the marks.
+// The list can be saved as "file:line" by save buffer
+// and jumps to the line by jump_origin.
+func (root *Root) listBookmarks() {
+	m := root.Doc
+	if len(m.bookmarks) == 0 && len(m.marked) == 0 {
+		root.setMessage("no bookmarks")
+		return
+	}
+	doc, err := m.bookmarkDocument()
+	if err != nil {
+		root.setMessageLogf("bookmark: %s", err)
+		return
+	}
+	root.addDocument(doc)
+}
@@ -0,0 +1,106 @@
+package oviewer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_parseBookmark(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		wantName string
+		wantNote string
+		wantErr  error
+	}{
+		{input: "a", wantName: "a"},
+		{input: "a connection lost", wantName: "a", wantNote: "connection lost"},
+		{input: "  b   retry  ", wantName: "b", wantNote: "retry"},
+		{input: "  ", wantErr: ErrInvalidBookmark},
+	}
+	for _, tt := range tests {
+		name, note, err := parseBookmark(tt.input)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("parseBookmark(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if name != tt.wantName || note != tt.wantNote {
+			t.Errorf("parseBookmark(%q) = %q, %q, want %q, %q", tt.input, name, note, tt.wantName, tt.wantNote)
+		}
+	}
+}
+
+func TestDocument_bookmarkList(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(fileName, []byte("line 1\nline 2\nline 3\nline 4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	m.marked = []int{3}
+	m.setBookmark("b", "", 2)
+	m.setBookmark("a", "start", 0)
+	// Moved to the line 1.
+	m.setBookmark("b", "retry", 1)
+	list, lineNumMap := m.bookmarkList()
+	want := m.filepath + ":1: 'a start\n" +
+		m.filepath + ":2: 'b retry\n" +
+		m.filepath + ":3: line 3\n" +
+		m.filepath + ":4: line 4\n"
+	if string(list) != want {
+		t.Errorf("bookmarkList() = %q, want %q", list, want)
+	}
+	if !reflect.DeepEqual(lineNumMap, []int{0, 1, 2, 3}) {
+		t.Errorf("bookmarkList() lineNumMap = %v, want [0 1 2 3]", lineNumMap)
+	}
+	if !m.deleteBookmarkLine(1) {
+		t.Error("deleteBookmarkLine(1) = false")
+	}
+	if _, ok := m.findBookmark("b"); ok {
+		t.Error("findBookmark(b) found the deleted bookmark")
+	}
+}
+
+func TestDocument_saveBookmarks(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(fileName, []byte("line 1\nline 2\nline 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	m.setBookmark("a", "start", 1)
+	m.saveBookmarks()
+
+	// Reopening the appended file restores the bookmarks.
+	appendTestFile(t, fileName, "line 4\n")
+	m2 := openIndexTestDocument(t, fileName)
+	if !reflect.DeepEqual(m2.bookmarks, m.bookmarks) {
+		t.Errorf("bookmarks = %v, want %v", m2.bookmarks, m.bookmarks)
+	}
+	if !reflect.DeepEqual(m2.marked, []int{1}) {
+		t.Errorf("marked = %v, want [1]", m2.marked)
+	}
+
+	// The truncated file is not the same file.
+	if err := os.WriteFile(fileName, []byte("new\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m3 := openIndexTestDocument(t, fileName)
+	if len(m3.bookmarks) != 0 {
+		t.Errorf("bookmarks = %v, want none", m3.bookmarks)
+	}
+
+	// No bookmarks removes the file.
+	m.bookmarks = nil
+	m.saveBookmarks()
+	path, err := bookmarkPath(m.filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("bookmark file exists: %v", err)
+	}
+}
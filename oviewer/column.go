@@ -0,0 +1,107 @@
+package oviewer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// columnBounds returns the start and end positions(x of contents) of each column of the line.
+func (m *Document) columnBounds(line LineC) [][2]int {
+	if m.ColumnWidth {
+		return columnWidthBounds(line.lc, m.columnWidths)
+	}
+	return columnDelimiterBounds(line, m.ColumnDelimiter, m.ColumnDelimiterReg)
+}
+
+// columnDelimiterBounds returns the bounds of the columns separated by the delimiter.
+// The delimiter itself is not included in the column.
+func columnDelimiterBounds(line LineC, delimiter string, delimiterReg *regexp.Regexp) [][2]int {
+	indexes := allIndex(line.str, delimiter, delimiterReg)
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	lStart := 0
+	// The leftmost fence is not a delimiter.
+	if indexes[0][0] == 0 {
+		lStart = indexes[0][1]
+		indexes = indexes[1:]
+	}
+
+	bounds := make([][2]int, 0, len(indexes)+1)
+	start := lStart
+	for _, idx := range indexes {
+		bounds = append(bounds, [2]int{line.pos.x(start), line.pos.x(idx[0])})
+		start = idx[1]
+	}
+	// The rightmost fence is not a delimiter.
+	if start < len(line.str) {
+		bounds = append(bounds, [2]int{line.pos.x(start), line.pos.x(len(line.str))})
+	}
+	return bounds
+}
+
+// columnWidthBounds returns the bounds of the columns separated by the widths.
+// Values that extend beyond the column position are included in the column.
+func columnWidthBounds(lc contents, widths []int) [][2]int {
+	if len(widths) == 0 {
+		return nil
+	}
+
+	bounds := make([][2]int, 0, len(widths)+1)
+	iStart, iEnd := 0, 0
+	for c := 0; c < len(widths)+1; c++ {
+		switch {
+		case c == 0:
+			iStart = 0
+			iEnd = findBounds(lc, widths[0]-1, widths, c)
+		case c < len(widths):
+			iStart = iEnd + 1
+			iEnd = findBounds(lc, widths[c], widths, c)
+		case c == len(widths):
+			iStart = iEnd + 1
+			iEnd = len(lc)
+		}
+		iStart = min(iStart, len(lc))
+		iEnd = min(iEnd, len(lc))
+		bounds = append(bounds, [2]int{iStart, iEnd})
+	}
+	return bounds
+}
+
+// headerColumnX returns the x position where the header columns of the line end.
+// If the line has no more columns than HeaderColumn, the whole line is the header columns.
+func (m *Document) headerColumnX(line LineC) int {
+	if m.HeaderColumn <= 0 {
+		return 0
+	}
+	bounds := m.columnBounds(line)
+	if len(bounds) == 0 {
+		return 0
+	}
+	if len(bounds) <= m.HeaderColumn {
+		return len(line.lc)
+	}
+	return bounds[m.HeaderColumn][0]
+}
+
+// columnString returns the string of the specified column of the line.
+// Surrounding spaces are removed.
+func (m *Document) columnString(line LineC, cursor int) (string, error) {
+	bounds := m.columnBounds(line)
+	if cursor < 0 || cursor >= len(bounds) {
+		return "", ErrNoColumn
+	}
+	b := bounds[cursor]
+	if b[0] >= b[1] {
+		return "", nil
+	}
+	str, _ := ContentsToStr(line.lc[b[0]:b[1]])
+	return strings.TrimSpace(str), nil
+}
+
+// bytesColumnString returns the string of the specified column from the bytes of a line.
+// It does not use the cache, so it is suitable for processing many lines.
+func (m *Document) bytesColumnString(buf []byte, cursor int) (string, error) {
+	return m.columnString(newLineC(string(buf), m.TabWidth), cursor)
+}
@@ -0,0 +1,186 @@
+package oviewer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// headerDetectLines is the number of lines to compare with the header row candidate.
+const headerDetectLines = 20
+
+// headerRowCache is the result of isHeaderRow.
+// The result is valid while the conditions of the detection are the same.
+type headerRowCache struct {
+	store     *store
+	delimiter string
+	widths    []int
+	lN        int
+	rows      int
+	width     bool
+	valid     bool
+	result    bool
+}
+
+// columnStrings returns the strings of all columns of the line.
+func (m *Document) columnStrings(line LineC) []string {
+	bounds := m.columnBounds(line)
+	strs := make([]string, 0, len(bounds))
+	for _, b := range bounds {
+		if b[0] >= b[1] {
+			strs = append(strs, "")
+			continue
+		}
+		str, _ := ContentsToStr(line.lc[b[0]:b[1]])
+		strs = append(strs, strings.TrimSpace(str))
+	}
+	return strs
+}
+
+// columnNameLN returns the line number of the line with the column names.
+// If Header is set, the last line of the header is used.
+// Otherwise, the first line is used if it looks like a header row.
+func (m *Document) columnNameLN() (int, bool) {
+	if m.Header > 0 {
+		return m.firstLine() - 1, true
+	}
+	if m.cachedHeaderRow(m.SkipLines) {
+		return m.SkipLines, true
+	}
+	return 0, false
+}
+
+// cachedHeaderRow returns the result of isHeaderRow.
+// It is detected again only when the line, the column settings
+// or the lines to compare (e.g. reloaded or read more) change.
+func (m *Document) cachedHeaderRow(lN int) bool {
+	c := &m.headerRow
+	rows := min(m.BufEndNum(), lN+1+headerDetectLines)
+	if c.valid && c.store == m.store && c.lN == lN && c.rows == rows &&
+		c.delimiter == m.ColumnDelimiter && c.width == m.ColumnWidth && equalInts(c.widths, m.columnWidths) {
+		return c.result
+	}
+	*c = headerRowCache{
+		store:     m.store,
+		delimiter: m.ColumnDelimiter,
+		widths:    append([]int(nil), m.columnWidths...),
+		lN:        lN,
+		rows:      rows,
+		width:     m.ColumnWidth,
+		valid:     true,
+		result:    m.isHeaderRow(lN),
+	}
+	return c.result
+}
+
+// equalInts returns whether the two slices are equal.
+func equalInts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// columnName returns the name of the specified column.
+func (m *Document) columnName(cursor int) (string, bool) {
+	lN, ok := m.columnNameLN()
+	if !ok {
+		return "", false
+	}
+	line, valid := m.getLineC(lN, m.TabWidth)
+	if !valid {
+		return "", false
+	}
+	name, err := m.columnString(line, cursor)
+	if err != nil || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// isHeaderRow returns whether the line looks like a header row.
+// The names of the header row must be non-empty, unique and not numeric.
+// Each column of the following lines votes:
+// a numeric(or time) column or a column of constant length
+// whose name has a different length is a vote for the header.
+func (m *Document) isHeaderRow(lN int) bool {
+	line, valid := m.getLineC(lN, m.TabWidth)
+	if !valid {
+		return false
+	}
+	names := m.columnStrings(line)
+	if len(names) < 2 {
+		return false
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] || isNumeric(name) || isTime(name) {
+			return false
+		}
+		seen[name] = true
+	}
+
+	rows := make([][]string, 0, headerDetectLines)
+	for n := lN + 1; n <= lN+headerDetectLines; n++ {
+		line, valid := m.getLineC(n, m.TabWidth)
+		if !valid {
+			break
+		}
+		rows = append(rows, m.columnStrings(line))
+	}
+	if len(rows) == 0 {
+		return false
+	}
+
+	score := 0
+	for c, name := range names {
+		numeric, sameLen := true, true
+		length, num := -1, 0
+		for _, row := range rows {
+			if c >= len(row) {
+				continue
+			}
+			num++
+			if !isNumeric(row[c]) && !isTime(row[c]) {
+				numeric = false
+			}
+			if length < 0 {
+				length = len(row[c])
+			} else if length != len(row[c]) {
+				sameLen = false
+			}
+			if !numeric && !sameLen {
+				break
+			}
+		}
+		switch {
+		case num == 0:
+			continue
+		case numeric:
+			score++
+		case sameLen:
+			if len(name) != length {
+				score++
+			} else {
+				score--
+			}
+		}
+	}
+	return score > 0
+}
+
+// isNumeric returns whether the string is a number.
+func isNumeric(str string) bool {
+	_, err := strconv.ParseFloat(strings.ReplaceAll(str, ",", ""), 64)
+	return err == nil
+}
+
+// isTime returns whether the string is a date or time.
+func isTime(str string) bool {
+	_, ok := parseTime(str)
+	return ok
+}
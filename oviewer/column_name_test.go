@@ -0,0 +1,161 @@
+package oviewer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDocument_columnName(t *testing.T) {
+	t.Parallel()
+	type fields struct {
+		header      int
+		skipLines   int
+		delimiter   string
+		columnWidth bool
+	}
+	tests := []struct {
+		name   string
+		str    string
+		fields fields
+		cursor int
+		want   string
+		wantOK bool
+	}{
+		{
+			name: "header",
+			str:  "name,city\nalice,tokyo\nbob,osaka\n",
+			fields: fields{
+				header:    1,
+				delimiter: ",",
+			},
+			cursor: 1,
+			want:   "city",
+			wantOK: true,
+		},
+		{
+			name: "detectNumeric",
+			str:  "name,age,score\nalice,20,1.5\nbob,31,2.25\ncarol,42,3\n",
+			fields: fields{
+				delimiter: ",",
+			},
+			cursor: 2,
+			want:   "score",
+			wantOK: true,
+		},
+		{
+			name: "detectSkipLines",
+			str:  "# comment\nid\tvalue\n1\t10\n2\t20\n",
+			fields: fields{
+				skipLines: 1,
+				delimiter: "\t",
+			},
+			cursor: 1,
+			want:   "value",
+			wantOK: true,
+		},
+		{
+			name: "noHeader",
+			str:  "1,2,3\n4,5,6\n7,8,9\n",
+			fields: fields{
+				delimiter: ",",
+			},
+			cursor: 0,
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name: "duplicateName",
+			str:  "a,a\n1,2\n3,4\n",
+			fields: fields{
+				delimiter: ",",
+			},
+			cursor: 0,
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name: "columnWidth",
+			str:  "PID   USER     TIME\n1     root     0:01\n23    nobody   0:00\n456   www      1:23\n",
+			fields: fields{
+				columnWidth: true,
+			},
+			cursor: 1,
+			want:   "USER",
+			wantOK: true,
+		},
+		{
+			name: "outOfRange",
+			str:  "name,city\nalice,tokyo\n",
+			fields: fields{
+				header:    1,
+				delimiter: ",",
+			},
+			cursor: 5,
+			want:   "",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.Header = tt.fields.header
+			m.SkipLines = tt.fields.skipLines
+			m.ColumnDelimiter = tt.fields.delimiter
+			m.ColumnWidth = tt.fields.columnWidth
+			if err := m.ControlReader(bytes.NewBufferString(tt.str), nil); err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+			}
+			if m.ColumnWidth {
+				m.setColumnWidths()
+			}
+			got, gotOK := m.columnName(tt.cursor)
+			if got != tt.want {
+				t.Errorf("Document.columnName() got = %v, want %v", got, tt.want)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("Document.columnName() gotOK = %v, want %v", gotOK, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDocument_cachedHeaderRow(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnDelimiter = ","
+	if err := m.ControlReader(bytes.NewBufferString("name,age\nalice,20\nbob,31\n"), nil); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	if !m.cachedHeaderRow(0) {
+		t.Fatal("Document.cachedHeaderRow() = false, want true")
+	}
+	if !m.headerRow.valid {
+		t.Fatal("Document.headerRow is not cached")
+	}
+	// The cached result is used while the conditions are the same.
+	m.headerRow.result = false
+	if m.cachedHeaderRow(0) {
+		t.Error("Document.cachedHeaderRow() did not use the cache")
+	}
+	// The delimiter change invalidates the cache.
+	m.ColumnDelimiter = "\t"
+	if m.cachedHeaderRow(0) {
+		t.Error("Document.cachedHeaderRow() = true with a different delimiter")
+	}
+	m.ColumnDelimiter = ","
+	if !m.cachedHeaderRow(0) {
+		t.Error("Document.cachedHeaderRow() = false, want true")
+	}
+}
@@ -0,0 +1,328 @@
+package oviewer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument_headerColumnX(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		str          string
+		headerColumn int
+		want         int
+	}{
+		{
+			name:         "noHeaderColumn",
+			str:          "a,bb,ccc",
+			headerColumn: 0,
+			want:         0,
+		},
+		{
+			name:         "headerColumn1",
+			str:          "a,bb,ccc",
+			headerColumn: 1,
+			want:         2,
+		},
+		{
+			name:         "headerColumn2",
+			str:          "a,bb,ccc",
+			headerColumn: 2,
+			want:         5,
+		},
+		{
+			name:         "allColumns",
+			str:          "a,bb,ccc",
+			headerColumn: 3,
+			want:         8,
+		},
+		{
+			name:         "noDelimiter",
+			str:          "abc",
+			headerColumn: 1,
+			want:         0,
+		},
+		{
+			name:         "wide",
+			str:          "あ,bb,ccc",
+			headerColumn: 1,
+			want:         3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ColumnDelimiter = ","
+			m.HeaderColumn = tt.headerColumn
+			if got := m.headerColumnX(newLineC(tt.str, m.TabWidth)); got != tt.want {
+				t.Errorf("Document.headerColumnX() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_columnString(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		str     string
+		cursor  int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "first",
+			str:     "a, bb ,ccc",
+			cursor:  0,
+			want:    "a",
+			wantErr: false,
+		},
+		{
+			name:    "trim",
+			str:     "a, bb ,ccc",
+			cursor:  1,
+			want:    "bb",
+			wantErr: false,
+		},
+		{
+			name:    "outOfRange",
+			str:     "a, bb ,ccc",
+			cursor:  3,
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ColumnDelimiter = ","
+			got, err := m.bytesColumnString([]byte(tt.str), tt.cursor)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Document.columnString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Document.columnString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_separatorFilter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		header string
+		pos    []int
+		num    int
+		want   []int
+	}{
+		{
+			name:   "singleSpace",
+			header: "CONTAINER ID   IMAGE     COMMAND",
+			pos:    []int{9, 14, 24},
+			num:    2,
+			want:   []int{14, 24},
+		},
+		{
+			name:   "allSeparator",
+			header: "NAME  AGE",
+			pos:    []int{5},
+			num:    2,
+			want:   []int{5},
+		},
+		{
+			name:   "outOfHeader",
+			header: "NAME",
+			pos:    []int{10},
+			num:    2,
+			want:   []int{10},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := separatorFilter(tt.header, tt.pos, tt.num); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("separatorFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toggleColumnBoundary(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		widths []int
+		p      int
+		want   []int
+	}{
+		{
+			name:   "add",
+			widths: []int{5, 20},
+			p:      10,
+			want:   []int{5, 10, 20},
+		},
+		{
+			name:   "remove",
+			widths: []int{5, 10, 20},
+			p:      10,
+			want:   []int{5, 20},
+		},
+		{
+			name:   "addEmpty",
+			widths: nil,
+			p:      3,
+			want:   []int{3},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := toggleColumnBoundary(tt.widths, tt.p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toggleColumnBoundary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_guessColumnWidths(t *testing.T) {
+	t.Parallel()
+	lines := []string{
+		"CONTAINER ID   IMAGE     STATUS",
+		"0123456789ab   nginx     Up 2 hours",
+		"ba9876543210   redis     Up 3 days",
+	}
+	tests := []struct {
+		name      string
+		minLines  int
+		separator int
+		want      []int
+	}{
+		{
+			name:      "default",
+			minLines:  0,
+			separator: 0,
+			want:      []int{14, 24},
+		},
+		{
+			name:      "minLines",
+			minLines:  1,
+			separator: 0,
+			want:      []int{9, 14, 24},
+		},
+		{
+			name:      "separator",
+			minLines:  1,
+			separator: 2,
+			want:      []int{14, 24},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ColumnWidthMinLines = tt.minLines
+			m.ColumnWidthSeparator = tt.separator
+			if got := m.guessColumnWidths(lines, 0); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.guessColumnWidths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_setColumnWidthsChunks(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lines to scan are in the second chunk.
+	m.SkipLines = ChunkSize
+	m.ColumnWidthScanLines = ChunkSize + 3
+	str := strings.Repeat("skip\n", ChunkSize) + "NAME   CITY\nalice  tokyo\nbob    osaka\n"
+	if err := m.ControlReader(strings.NewReader(str), nil); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	m.setColumnWidths()
+	if want := []int{6}; !reflect.DeepEqual(m.columnWidths, want) {
+		t.Errorf("Document.setColumnWidths() = %v, want %v", m.columnWidths, want)
+	}
+}
+
+func TestDocument_setColumnWidthsLoaded(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the lines after SkipLines are scanned.
+	m.SkipLines = 2
+	m.ColumnWidthScanLines = 3
+	str := "a b c d e f g\nxxxxxxxxxxxxx\nNAME   CITY\nalice  tokyo\nbob    osaka\nxxxxxxxxxxxxxxxxx\n"
+	if err := m.ControlReader(strings.NewReader(str), nil); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	m.setColumnWidths()
+	if want := []int{6}; !reflect.DeepEqual(m.columnWidths, want) {
+		t.Errorf("Document.setColumnWidths() = %v, want %v", m.columnWidths, want)
+	}
+
+}
+
+func TestDocument_rangeLoadedLines(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "column.txt")
+	var b strings.Builder
+	for i := 0; i < ChunkSize*2+10; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	m.store.unloadChunk(1)
+	got := 0
+	m.rangeLoadedLines(ChunkSize-5, ChunkSize+5, func(lN int, _ []byte) {
+		if lN/ChunkSize == 1 {
+			t.Errorf("line %d of the chunk not in memory", lN)
+		}
+		got++
+	})
+	// The chunk not in memory is skipped without loading.
+	if want := 5; got != want {
+		t.Errorf("Document.rangeLoadedLines() = %d lines, want %d", got, want)
+	}
+	if len(m.store.chunks[1].lines) != 0 {
+		t.Errorf("chunk 1 is loaded")
+	}
+}
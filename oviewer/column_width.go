@@ -0,0 +1,186 @@
+package oviewer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/noborus/guesswidth"
+)
+
+const (
+	// defaultColumnWidthScanLines is the default number of lines to scan to guess the column widths.
+	defaultColumnWidthScanLines = 1000
+	// defaultColumnWidthMinLines is the default minimum number of lines
+	// that must be blank at the same position to be a column boundary.
+	defaultColumnWidthMinLines = 2
+)
+
+// guessColumnWidths returns the column boundaries guessed from the lines.
+// header is the line number of the header in lines.
+func (m *Document) guessColumnWidths(lines []string, header int) []int {
+	minLines := m.ColumnWidthMinLines
+	if minLines <= 0 {
+		minLines = defaultColumnWidthMinLines
+	}
+	pos := guesswidth.Positions(lines, header, minLines)
+	if m.ColumnWidthSeparator > 1 && header < len(lines) {
+		pos = separatorFilter(lines[header], pos, m.ColumnWidthSeparator)
+	}
+	return pos
+}
+
+// separatorFilter returns only the boundaries
+// where the header has at least num consecutive spaces.
+// This excludes the boundaries of the header that contains a single space (e.g. "CONTAINER ID").
+func separatorFilter(header string, pos []int, num int) []int {
+	blanks := make([]bool, 0, len(header))
+	for _, r := range header {
+		blanks = append(blanks, r == ' ')
+		if runewidth.RuneWidth(r) == 2 {
+			blanks = append(blanks, false)
+		}
+	}
+
+	filtered := make([]int, 0, len(pos))
+	for _, p := range pos {
+		if p >= len(blanks) {
+			filtered = append(filtered, p)
+			continue
+		}
+		spaces := 0
+		for i := p; i >= 0 && blanks[i]; i-- {
+			spaces++
+		}
+		for i := p + 1; i < len(blanks) && blanks[i]; i++ {
+			spaces++
+		}
+		if spaces >= num {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// detectColumnWidths guesses the column widths again from the lines displayed on the screen.
+func (root *Root) detectColumnWidths() {
+	m := root.Doc
+	if !m.ColumnWidth {
+		root.setMessage("column width mode is not enabled")
+		return
+	}
+
+	lines := make([]string, 0, root.scr.vHeight)
+	for lN := m.SkipLines; lN < m.firstLine(); lN++ {
+		line, _ := m.getLineC(lN, m.TabWidth)
+		lines = append(lines, line.str)
+	}
+	header := max(len(lines)-1, 0)
+	for lN := m.topLN + m.firstLine(); lN < m.bottomLN; lN++ {
+		line, valid := m.getLineC(lN, m.TabWidth)
+		if !valid {
+			break
+		}
+		lines = append(lines, line.str)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	m.columnWidths = m.guessColumnWidths(lines, header)
+	m.columnCursor = min(m.columnCursor, len(m.columnWidths))
+	root.setMessagef("Detect column widths %v", m.columnWidths)
+}
+
+// setColumnBoundary adds or removes column boundaries.
+// The input is the positions of the boundaries separated by spaces.
+// Existing boundaries are removed and new ones are added.
+func (root *Root) setColumnBoundary(input string) {
+	m := root.Doc
+	if !m.ColumnWidth {
+		root.setMessage("column width mode is not enabled")
+		return
+	}
+
+	widths := m.columnWidths
+	for _, f := range strings.Fields(input) {
+		p, err := strconv.Atoi(f)
+		if err != nil {
+			root.setMessagef("Set column boundary: %s", ErrInvalidNumber.Error())
+			return
+		}
+		if p <= 0 {
+			root.setMessagef("Set column boundary %d: %s", p, ErrOutOfRange.Error())
+			return
+		}
+		widths = toggleColumnBoundary(widths, p)
+	}
+	m.columnWidths = widths
+	m.columnCursor = min(m.columnCursor, len(m.columnWidths))
+	root.setMessagef("Set column boundaries %v", m.columnWidths)
+}
+
+// toggleColumnBoundary removes p if it exists in widths, otherwise adds it.
+// The returned widths are sorted.
+func toggleColumnBoundary(widths []int, p int) []int {
+	newWidths := make([]int, 0, len(widths)+1)
+	found := false
+	for _, w := range widths {
+		if w == p {
+			found = true
+			continue
+		}
+		newWidths = append(newWidths, w)
+	}
+	if !found {
+		newWidths = append(newWidths, p)
+		sort.Ints(newWidths)
+	}
+	return newWidths
+}
+
+// drawColumnRuler draws a ruler showing the column boundaries on the line y.
+func (root *Root) drawColumnRuler(y int) {
+	m := root.Doc
+	startX := 0
+	if !m.WrapMode {
+		startX = max(m.x, 0)
+	}
+	boundaries := make(map[int]bool, len(m.columnWidths))
+	for _, w := range m.columnWidths {
+		boundaries[w] = true
+	}
+
+	rulerStyle := applyStyle(tcell.StyleDefault, root.StyleHeader)
+	markStyle := rulerStyle.Reverse(true)
+	label := ""
+	for x := 0; root.scr.startX+x < root.scr.vWidth; x++ {
+		pos := startX + x
+		r := '.'
+		if pos%10 == 0 {
+			label = strconv.Itoa(pos)
+			r = '+'
+		}
+		if len(label) > 0 && pos%10 != 0 {
+			r = rune(label[0])
+			label = label[1:]
+		}
+		style := rulerStyle
+		if boundaries[pos] {
+			r = '|'
+			style = markStyle
+		}
+		root.Screen.SetContent(root.scr.startX+x, y, r, nil, style)
+	}
+}
+
+// columnBoundaryList returns the column boundaries as a list of strings.
+func columnBoundaryList(widths []int) []string {
+	list := make([]string, 0, len(widths))
+	for _, w := range widths {
+		list = append(list, fmt.Sprint(w))
+	}
+	return list
+}
@@ -0,0 +1,110 @@
+package oviewer
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// combinedMarkers is the gutter markers of stdout and stderr in the combined document.
+var combinedMarkers = [2][]byte{
+	[]byte("  "),
+	[]byte("\x1b[31mE\x1b[0m "),
+}
+
+// combinedDocument interleaves the lines of stdout and stderr in arrival order.
+type combinedDocument struct {
+	doc *Document
+
+	mu sync.Mutex
+	// partial is the last line of each stream that has not been terminated yet.
+	partial [2][]byte
+}
+
+// combinedStream is the writer of one stream to the combined document.
+type combinedStream struct {
+	cd  *combinedDocument
+	num int
+}
+
+// newCombinedDocument returns combinedDocument.
+func newCombinedDocument() (*combinedDocument, error) {
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = "COMBINED"
+	doc.seekable = false
+	doc.preventReload = true
+	cd := &combinedDocument{doc: doc}
+	doc.combined = cd
+	if err := doc.ControlLog(); err != nil {
+		return nil, err
+	}
+	return cd, nil
+}
+
+// stream returns the writer of stdout(0) or stderr(1).
+func (cd *combinedDocument) stream(num int) *combinedStream {
+	return &combinedStream{cd: cd, num: num}
+}
+
+// Write appends the terminated lines to the combined document.
+func (w *combinedStream) Write(p []byte) (int, error) {
+	cd := w.cd
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	sep, _ := cd.doc.store.separator()
+	buf := append(cd.partial[w.num], p...)
+	for {
+		i := bytes.IndexByte(buf, sep)
+		if i < 0 {
+			break
+		}
+		cd.appendLine(w.num, buf[:i+1])
+		buf = buf[i+1:]
+	}
+	cd.partial[w.num] = append([]byte(nil), buf...)
+	return len(p), nil
+}
+
+// flush appends the last line of the stream that has no separator.
+func (cd *combinedDocument) flush(num int) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	if len(cd.partial[num]) == 0 {
+		return
+	}
+	sep, _ := cd.doc.store.separator()
+	cd.appendLine(num, append(cd.partial[num], sep))
+	cd.partial[num] = nil
+}
+
+// appendLine appends the line with the marker of the stream.
+func (cd *combinedDocument) appendLine(num int, line []byte) {
+	s := cd.doc.store
+	buf := make([]byte, 0, len(combinedMarkers[num])+len(line))
+	buf = append(buf, combinedMarkers[num]...)
+	buf = append(buf, line...)
+	chunk := s.chunkForAdd(false, s.size)
+	s.append(chunk, true, buf)
+	atomic.StoreInt32(&s.eof, 1)
+	atomic.StoreInt32(&s.changed, 1)
+}
+
+// rerun prepares the combined document for the next execution.
+// In watch mode, the output is added after the FormFeed, otherwise it is cleared.
+func (cd *combinedDocument) rerun(watch bool) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	cd.partial = [2][]byte{}
+	m := cd.doc
+	if watch {
+		s := m.store
+		s.appendFormFeed(s.chunkForAdd(false, s.size))
+		atomic.StoreInt32(&s.changed, 1)
+		return
+	}
+	atomic.StoreInt32(&m.store.eof, 1)
+	m.reset()
+}
@@ -53,6 +53,9 @@ func NewConfig() Config {
 		StyleJumpTargetLine: OVStyle{
 			Underline: true,
 		},
+		StyleWatchDiff: OVStyle{
+			Reverse: true,
+		},
 		General: general{
 			TabWidth:       8,
 			MarkStyleWidth: 1,
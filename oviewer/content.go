@@ -70,12 +70,9 @@ type parseState struct {
 	bsFlag    bool // backspace(^H) flag
 }
 
-// parseString converts a string to lineContents.
-// parseString includes escape sequences and tabs.
-// If tabwidth is set to -1, \t is displayed instead of functioning as a tab.
-func parseString(str string, tabWidth int) contents {
-	lc := make(contents, 0, len(str))
-	state := &parseState{
+// newParseState returns the initial state of the parser.
+func newParseState() *parseState {
+	return &parseState{
 		state:     ansiText,
 		parameter: strings.Builder{},
 		url:       strings.Builder{},
@@ -84,9 +81,28 @@ func parseString(str string, tabWidth int) contents {
 		bsFlag:    false,
 		bsContent: DefaultContent,
 	}
+}
+
+// parseString converts a string to lineContents.
+// parseString includes escape sequences and tabs.
+// If tabwidth is set to -1, \t is displayed instead of functioning as a tab.
+func parseString(str string, tabWidth int) contents {
+	lc := make(contents, 0, len(str))
+	lc, _ = newParseState().parse(lc, str, tabWidth, -1)
+	return lc
+}
 
+// parse converts a string and appends it to lc.
+// parse stops before the character that follows when lc reaches limit,
+// and returns the number of bytes converted.
+// If limit is -1, the entire string is converted.
+func (state *parseState) parse(lc contents, str string, tabWidth int, limit int) (contents, int) {
 	gr := uniseg.NewGraphemes(str)
 	for gr.Next() {
+		if limit >= 0 && len(lc) >= limit {
+			from, _ := gr.Positions()
+			return lc, from
+		}
 		r := gr.Runes()
 		mainc := r[0]
 		combc := r[1:]
@@ -171,7 +187,7 @@ func parseString(str string, tabWidth int) contents {
 			state.tabx += 2
 		}
 	}
-	return lc
+	return lc, len(str)
 }
 
 // parseEscapeSequence parses an escape sequence and changes state.
@@ -35,6 +35,7 @@ const (
 // ControlFile controls file read and loads in chunks.
 // ControlFile can be reloaded by file name.
 func (m *Document) ControlFile(file *os.File) error {
+	m.setDefaultSeparator()
 	m.memoryLimit = loadChunksCapacity(m.seekable)
 	m.store.setNewLoadChunks(m.memoryLimit)
 	atomic.StoreInt32(&m.closed, 0)
@@ -45,26 +46,46 @@ func (m *Document) ControlFile(file *os.File) error {
 	}
 	atomic.StoreInt32(&m.store.eof, 0)
 
-	go func() {
-		reader := bufio.NewReader(r)
-		for sc := range m.ctlCh {
-			reader, err = m.controlFile(sc, reader)
-			if sc.done != nil {
-				if err != nil {
-					sc.done <- false
-				} else {
-					sc.done <- true
-				}
-				close(sc.done)
-			}
-		}
-		log.Println("close m.ctlCh")
-	}()
+	go m.controlFileLoop(bufio.NewReader(r))
+
+	m.requestStart()
+	return nil
+}
+
+// ControlSeeker controls io.ReadSeeker like a file.
+// The contents are loaded in chunks by seeking, like a regular file.
+func (m *Document) ControlSeeker(rs io.ReadSeeker) error {
+	m.seekable = true
+	m.reopenable = false
+	m.seeker = rs
+	m.memoryLimit = loadChunksCapacity(m.seekable)
+	m.store.setNewLoadChunks(m.memoryLimit)
+	atomic.StoreInt32(&m.closed, 0)
+	atomic.StoreInt32(&m.store.eof, 0)
+
+	go m.controlFileLoop(bufio.NewReader(rs))
 
 	m.requestStart()
 	return nil
 }
 
+// controlFileLoop executes the requests for the file until ctlCh is closed.
+func (m *Document) controlFileLoop(reader *bufio.Reader) {
+	var err error
+	for sc := range m.ctlCh {
+		reader, err = m.controlFile(sc, reader)
+		if sc.done != nil {
+			if err != nil {
+				sc.done <- false
+			} else {
+				sc.done <- true
+			}
+			close(sc.done)
+		}
+	}
+	log.Println("close m.ctlCh")
+}
+
 // ControlReader is the controller for io.Reader.
 // Assuming call from Exec. reload executes the argument function.
 func (m *Document) ControlReader(r io.Reader, reload func() *bufio.Reader) error {
@@ -92,7 +113,9 @@ func (m *Document) ControlReader(r io.Reader, reload func() *bufio.Reader) error
 		log.Println("close ctlCh")
 	}()
 
-	m.requestStart()
+	if atomic.LoadInt32(&m.deferred) == 0 {
+		m.requestStart()
+	}
 	return nil
 }
 
@@ -131,7 +154,7 @@ func (m *Document) controlFile(sc controlSpecifier, reader *bufio.Reader) (*bufi
 		if !m.store.isContinueRead(m.memoryLimit) {
 			return reader, nil
 		}
-		if m.seekable && atomic.LoadInt32(&m.tmpFollow) == 0 && (m.FollowMode || m.FollowAll) {
+		if m.tailReadable() && atomic.LoadInt32(&m.tmpFollow) == 0 && (m.FollowMode || m.FollowAll) {
 			go func() {
 				m.requestBottom()
 			}()
@@ -170,6 +193,10 @@ func (m *Document) controlReader(sc controlSpecifier, reader *bufio.Reader, relo
 	case requestLoad:
 		// Since controlReader is loaded outside, it only evicts.
 		m.store.evictChunksMem(sc.chunkNum)
+	case requestClose:
+		atomic.StoreInt32(&m.closed, 1)
+		atomic.StoreInt32(&m.store.changed, 1)
+		m.releaseArchive()
 	case requestReload:
 		if reload != nil {
 			log.Println("reload")
@@ -190,6 +217,9 @@ func (m *Document) controlLog(sc controlSpecifier) {
 	case requestFollow:
 	case requestReload:
 		m.reset()
+	case requestClose:
+		atomic.StoreInt32(&m.closed, 1)
+		atomic.StoreInt32(&m.store.changed, 1)
 	default:
 		panic(fmt.Sprintf("unexpected %s", sc.request))
 	}
@@ -234,6 +264,18 @@ func (m *Document) requestLoad(chunkNum int) {
 	}()
 }
 
+// requestLoadSync sends instructions to load chunks into memory
+// and waits until loading is complete.
+func (m *Document) requestLoadSync(chunkNum int) bool {
+	sc := controlSpecifier{
+		request:  requestLoad,
+		chunkNum: chunkNum,
+		done:     make(chan bool),
+	}
+	m.ctlCh <- sc
+	return <-sc.done
+}
+
 // requestSearch sends instructions to load chunks into memory.
 func (m *Document) requestSearch(chunkNum int, searcher Searcher) bool {
 	sc := controlSpecifier{
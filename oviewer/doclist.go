@@ -27,7 +27,16 @@ func (root *Root) hasDocChanged() bool {
 // addDocument adds a document and displays it.
 func (root *Root) addDocument(m *Document) {
 	root.setMessageLogf("add %s", m.FileName)
-	m.general = root.Config.General
+	// The derived document inherits the settings of the parent.
+	if m.parent != nil {
+		m.general = m.parent.general
+	} else {
+		m.general = root.Config.General
+	}
+	// The files opened by FollowGlob are followed.
+	if root.matchGlob(m.filepath) {
+		m.FollowMode = true
+	}
 	m.regexpCompile()
 
 	root.mu.Lock()
@@ -36,6 +45,7 @@ func (root *Root) addDocument(m *Document) {
 	root.mu.Unlock()
 
 	root.setDocument(m)
+	root.closeGlobWaiting(m)
 }
 
 // closeDocument closes the document.
@@ -49,6 +59,7 @@ func (root *Root) closeDocument() {
 	root.setMessageLogf("close [%d]%s", root.CurrentDoc, root.Doc.FileName)
 	root.mu.Lock()
 	root.DocList[root.CurrentDoc].requestClose()
+	root.DocList[root.CurrentDoc].store.release()
 	root.DocList = append(root.DocList[:root.CurrentDoc], root.DocList[root.CurrentDoc+1:]...)
 	if root.CurrentDoc > 0 {
 		root.CurrentDoc--
@@ -96,6 +107,7 @@ func (root *Root) setDocumentNum(docNum int) {
 // setDocument sets the Document.
 func (root *Root) setDocument(m *Document) {
 	root.Doc = m
+	m.startDeferred()
 	root.ViewSync()
 }
 
@@ -1,12 +1,13 @@
 package oviewer
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sync"
 	"sync/atomic"
@@ -14,7 +15,6 @@ import (
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/jwalton/gchalk"
-	"github.com/noborus/guesswidth"
 )
 
 // The Document structure contains the values
@@ -24,6 +24,8 @@ type Document struct {
 	file *os.File
 
 	cache *lru.Cache[int, LineC]
+	// timeCache is the cache of the previous timestamp of each line for the relative time.
+	timeCache *lru.Cache[int, prevTimestamp]
 
 	ticker     *time.Ticker
 	tickerDone chan struct{}
@@ -43,8 +45,17 @@ type Document struct {
 	// filepath stores the absolute pathname for file watching.
 	filepath string
 
+	// parent is the original document of the derived document.
+	parent *Document
+	// lineNumMap is a map of line numbers from the derived document to the parent document.
+	lineNumMap []int
+
 	// marked is a list of marked line numbers.
 	marked []int
+	// bookmarks is a list of named bookmarks.
+	bookmarks []bookmark
+	// headerRow is the cache of the header row detection.
+	headerRow headerRowCache
 	// columnWidths is a slice of column widths.
 	columnWidths []int
 
@@ -53,6 +64,11 @@ type Document struct {
 
 	// memoryLimit is the maximum chunk size.
 	memoryLimit int
+	// rotatedFiles is the files replaced while following with KeepRotated.
+	// Their chunks are read from the files kept open.
+	rotatedFiles []rotatedFile
+	// indexNum is the number of lines saved in the index cache.
+	indexNum int
 
 	// currentChunk represents the current chunk number.
 	currentChunk int
@@ -94,6 +110,11 @@ type Document struct {
 
 	// CFormat is a compressed format.
 	CFormat Compressed
+	// Encoding is the character encoding of the document.
+	Encoding string
+	// specEncoding is the character encoding specified for the document.
+	// If empty, the global Encoding is used.
+	specEncoding string
 
 	watchRestart int32
 	tickerState  int32
@@ -104,6 +125,19 @@ type Document struct {
 	tmpFollow int32
 	// tmpLN is a temporary line number when the number of lines is undetermined.
 	tmpLN int32
+	// 1 if the beginning of the file looks like binary.
+	binary int32
+	// 1 if the binary has already been notified.
+	binaryNotified int32
+
+	// hex is the source of the hex dump if the document is a hex view.
+	hex *hexDump
+	// merge is the source documents if the document is a merged view.
+	merge *mergeDocument
+	// command is the executed command if the document is the output of the command.
+	command *Command
+	// combined is the interleaved stdout and stderr if the document is a combined view.
+	combined *combinedDocument
 
 	// WatchMode is watch mode.
 	WatchMode bool
@@ -111,8 +145,20 @@ type Document struct {
 	preventReload bool
 	// Is it possible to seek.
 	seekable bool
+	// seeker is used to read the file from the start of the chunk.
+	// It is the file itself or the reader of the uncompressed contents.
+	seeker io.ReadSeeker
 	// Is it possible to reopen.
 	reopenable bool
+	// indexCache is IndexCache when the document is created,
+	// because the index is read and saved in the background.
+	indexCache bool
+	// archived is true if the document is a member of an archive.
+	archived bool
+	// archive is the archive file read by the member document.
+	archive *archiveFile
+	// deferred is 1 if reading is deferred until the document is displayed.
+	deferred int32
 }
 
 // store represents store management.
@@ -143,6 +189,29 @@ type store struct {
 	offset int64
 	// formfeedTime adds time on formfeed.
 	formfeedTime bool
+	// snapshots is the history of the snapshots in watch mode.
+	snapshots []watchSnapshot
+	// loadedBytes is the number of bytes of lines held in memory.
+	loadedBytes int64
+	// sep is the byte that separates records (lines).
+	sep byte
+	// crlf removes CR before LF from the lines.
+	crlf bool
+	// pinned is the chunks that are not unloaded,
+	// because they cannot be read again from the file (e.g. rotated).
+	pinned map[int]bool
+	// mmap is the file read by mapping. nil if the file is not mapped.
+	mmap *mmapFile
+	// loading is the number of the chunk being loaded (-1 if none).
+	loading int32
+	// view returns the lines instead of the chunks
+	// if the lines refer to other documents (e.g. the merged document).
+	view lineViewer
+}
+
+// lineViewer returns the line of the document that refers to other documents.
+type lineViewer interface {
+	viewLine(lN int) ([]byte, error)
 }
 
 // chunk stores the contents of the split file as slices of strings.
@@ -152,6 +221,14 @@ type chunk struct {
 	lines [][]byte
 	// start is the first position of the number of bytes read.
 	start int64
+	// bytes is the number of bytes of lines held in memory.
+	bytes int64
+	// mapped is the region of the file mapped into memory.
+	// lines refer to it if it is not nil.
+	mapped []byte
+	// unverified is true if start is read from the index cache
+	// and has not been checked to be the beginning of a line.
+	unverified bool
 }
 
 // LineC is one line of information.
@@ -160,6 +237,23 @@ type LineC struct {
 	lc  contents
 	str string
 	pos widthPos
+	// lazy is not nil if the rest of the long line has not been converted.
+	lazy *lazyLine
+}
+
+// newLineC returns LineC from the string of one line.
+func newLineC(str string, tabWidth int) LineC {
+	return contentsToLineC(parseString(str, tabWidth))
+}
+
+// contentsToLineC returns LineC from contents.
+func contentsToLineC(lc contents) LineC {
+	str, pos := ContentsToStr(lc)
+	return LineC{
+		lc:  lc,
+		str: str,
+		pos: pos,
+	}
 }
 
 // NewDocument returns Document.
@@ -177,6 +271,7 @@ func NewDocument() (*Document, error) {
 		seekable:      true,
 		reopenable:    true,
 		preventReload: false,
+		indexCache:    IndexCache,
 		store:         NewStore(),
 	}
 	if err := m.NewCache(); err != nil {
@@ -192,6 +287,11 @@ func (m *Document) NewCache() error {
 		return fmt.Errorf("new cache %w", err)
 	}
 	m.cache = cache
+	timeCache, err := lru.New[int, prevTimestamp](1024)
+	if err != nil {
+		return fmt.Errorf("new cache %w", err)
+	}
+	m.timeCache = timeCache
 
 	return nil
 }
@@ -227,6 +327,10 @@ func OpenDocument(fileName string) (*Document, error) {
 	}
 
 	m.FileName = fileName
+	if path, err := filepath.Abs(fileName); err == nil {
+		m.filepath = path
+	}
+	m.loadBookmarks()
 	// Read the control file.
 	if err := m.ControlFile(f); err != nil {
 		return nil, err
@@ -270,7 +374,7 @@ func (m *Document) Line(n int) ([]byte, error) {
 	if s.lastChunkNum() < chunkNum {
 		return nil, fmt.Errorf("%w %d<%d", ErrOutOfRange, s.lastChunkNum(), chunkNum)
 	}
-	if m.currentChunk != chunkNum {
+	if m.currentChunk != chunkNum || s.isStaleMapped(chunkNum) {
 		m.currentChunk = chunkNum
 		m.requestLoad(chunkNum)
 	}
@@ -280,6 +384,9 @@ func (m *Document) Line(n int) ([]byte, error) {
 
 // GetChunkLine returns one line from buffer.
 func (s *store) GetChunkLine(chunkNum int, cn int) ([]byte, error) {
+	if s.view != nil {
+		return s.view.viewLine(chunkNum*ChunkSize + cn)
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if len(s.chunks) <= chunkNum {
@@ -290,7 +397,10 @@ func (s *store) GetChunkLine(chunkNum int, cn int) ([]byte, error) {
 	if cn >= len(chunk.lines) {
 		return nil, fmt.Errorf("over line (%d:%d) %w", chunkNum, cn, ErrOutOfRange)
 	}
-	return bytes.TrimSuffix(chunk.lines[cn], []byte("\n")), nil
+	if chunk.mapped != nil {
+		return s.mappedLine(chunk.lines[cn])
+	}
+	return trimSeparator(chunk.lines[cn], s.sep, s.crlf), nil
 }
 
 // GetLine returns one line from buffer.
@@ -324,8 +434,15 @@ func (m *Document) CurrentLN() int {
 }
 
 // Export exports the document in the specified range.
+// Records are written as they are, including the record separator.
 func (m *Document) Export(w io.Writer, start int, end int) error {
 	end = min(end, m.BufEndNum()-1)
+	if m.store.view != nil {
+		return m.rangeLines(context.Background(), start, end+1, func(_ int, line []byte) error {
+			_, err := fmt.Fprintf(w, "%s\n", line)
+			return err
+		})
+	}
 	startChunk, startCn := chunkLineNum(start)
 	endChunk, endCn := chunkLineNum(end)
 
@@ -336,7 +453,13 @@ func (m *Document) Export(w io.Writer, start int, end int) error {
 			ecn = endCn + 1
 		}
 		chunk := m.store.chunks[chunkNum]
-		if err := m.store.export(w, chunk, scn, ecn); err != nil {
+		var err error
+		// The lines of the mapped file may fault if the file has been truncated.
+		if ferr := faultSafe(func() { err = m.store.export(w, chunk, scn, ecn) }); ferr != nil {
+			m.store.failMmap()
+			return ferr
+		}
+		if err != nil {
 			return err
 		}
 		scn = 0
@@ -344,6 +467,60 @@ func (m *Document) Export(w io.Writer, start int, end int) error {
 	return nil
 }
 
+// rangeLines calls fn for each line from start to end(not including end).
+// Chunks that are not in memory are loaded before reading.
+func (m *Document) rangeLines(ctx context.Context, start int, end int, fn func(lN int, line []byte) error) error {
+	start = max(start, m.BufStartNum())
+	end = min(end, m.storeEndNum())
+	for lN := start; lN < end; {
+		select {
+		case <-ctx.Done():
+			return ErrCancel
+		default:
+		}
+
+		chunkNum, cn := chunkLineNum(lN)
+		if !m.store.isLoadedChunk(chunkNum, m.seekable) {
+			if !m.requestLoadSync(chunkNum) {
+				return fmt.Errorf("%w %d", ErrNotLoaded, chunkNum)
+			}
+		}
+		for ; cn < ChunkSize && lN < end; cn++ {
+			line, err := m.store.GetChunkLine(chunkNum, cn)
+			if err != nil {
+				return err
+			}
+			if err := fn(lN, line); err != nil {
+				return err
+			}
+			lN++
+		}
+	}
+	return nil
+}
+
+// rangeLoadedLines calls fn for each line from start to end(not including end)
+// in the chunks in memory. Chunks that are not in memory are skipped without loading.
+func (m *Document) rangeLoadedLines(start int, end int, fn func(lN int, line []byte)) {
+	start = max(start, m.BufStartNum())
+	end = min(end, m.storeEndNum())
+	for lN := start; lN < end; {
+		chunkNum, cn := chunkLineNum(lN)
+		if !m.store.isLoadedChunk(chunkNum, m.seekable) {
+			lN += ChunkSize - cn
+			continue
+		}
+		for ; cn < ChunkSize && lN < end; cn++ {
+			line, err := m.store.GetChunkLine(chunkNum, cn)
+			if err != nil {
+				return
+			}
+			fn(lN, line)
+			lN++
+		}
+	}
+}
+
 // BufStartNum return start line number.
 func (m *Document) BufStartNum() int {
 	return int(atomic.LoadInt32(&m.store.startNum))
@@ -370,29 +547,36 @@ func (m *Document) BufEOF() bool {
 // ClearCache clears the cache.
 func (m *Document) ClearCache() {
 	m.cache.Purge()
+	m.timeCache.Purge()
 }
 
 // contents returns contents from line number and tabWidth.
+// The line is limited by LineLimit.
 func (m *Document) contents(lN int, tabWidth int) (contents, error) {
 	if lN < 0 || lN >= m.BufEndNum() {
 		return nil, ErrOutOfRange
 	}
 
 	str, err := m.LineStr(lN)
-	return parseString(str, tabWidth), err
+	str, truncated := limitLine(str, LineLimit)
+	lc := parseString(str, tabWidth)
+	if truncated > 0 {
+		lc = append(lc, truncatedContents(truncated)...)
+	}
+	return lc, err
 }
 
 // getLineC returns contents from line number and tabWidth.
 // If the line number does not exist, EOF content is returned.
+// Long lines are converted only up to the width displayed on the screen.
 func (m *Document) getLineC(lN int, tabWidth int) (LineC, bool) {
-	if line, ok := m.cache.Get(lN); ok {
-		lc := make(contents, len(line.lc))
-		copy(lc, line.lc)
-		line.lc = lc
-		return line, true
-	}
+	return m.getLineCWidth(lN, tabWidth, m.lineWindow())
+}
 
-	org, err := m.contents(lN, tabWidth)
+// getLineCWidth returns contents from line number and tabWidth
+// converted at least up to width.
+func (m *Document) getLineCWidth(lN int, tabWidth int, width int) (LineC, bool) {
+	line, err := m.lineCWidth(lN, tabWidth, width)
 	if err != nil && errors.Is(err, ErrOutOfRange) {
 		lc := make(contents, 1)
 		lc[0] = EOFContent
@@ -402,22 +586,22 @@ func (m *Document) getLineC(lN int, tabWidth int) (LineC, bool) {
 			pos: widthPos{0: 0, 1: 1},
 		}, false
 	}
-	str, pos := ContentsToStr(org)
-	line := LineC{
-		lc:  org,
-		str: str,
-		pos: pos,
-	}
-	if err == nil {
-		m.cache.Add(lN, line)
-	}
 
-	lc := make(contents, len(org))
-	copy(lc, org)
+	lc := make(contents, len(line.lc))
+	copy(lc, line.lc)
 	line.lc = lc
 	return line, true
 }
 
+// originLN returns the line number of the parent document.
+// Returns the line number as it is if it is not a derived document.
+func (m *Document) originLN(lN int) int {
+	if lN < 0 || lN >= len(m.lineNumMap) {
+		return lN
+	}
+	return m.lineNumMap[lN]
+}
+
 // firstLine is the first line that excludes the SkipLines and Header.
 func (m *Document) firstLine() int {
 	return m.SkipLines + m.Header
@@ -466,7 +650,10 @@ func (m *Document) setMultiColorWords(words []string) {
 }
 
 // setColumnWidths sets the column widths.
-// Guess the width of the columns using the first 1000 lines (maximum) and the headers.
+// Guess the width of the columns using ColumnWidthScanLines lines (default 1000)
+// after SkipLines and the headers.
+// It is called while drawing, so only the lines of the chunks in memory are used
+// and the chunks are not loaded.
 func (m *Document) setColumnWidths() {
 	if m.BufEndNum() == 0 {
 		return
@@ -474,11 +661,14 @@ func (m *Document) setColumnWidths() {
 
 	header := m.Header - 1
 	header = max(header, 0)
-	tl := min(1000, len(m.store.chunks[0].lines))
-	lines := m.store.chunks[0].lines[m.SkipLines:tl]
-	buf := make([]string, len(lines))
-	for n, line := range lines {
-		buf[n] = string(line)
-	}
-	m.columnWidths = guesswidth.Positions(buf, header, 2)
+	scanLines := m.ColumnWidthScanLines
+	if scanLines <= 0 {
+		scanLines = defaultColumnWidthScanLines
+	}
+	sep, crlf := m.store.separator()
+	buf := make([]string, 0, min(scanLines, m.BufEndNum()))
+	m.rangeLoadedLines(m.SkipLines, m.SkipLines+scanLines, func(_ int, line []byte) {
+		buf = append(buf, string(trimSeparator(line, sep, crlf)))
+	})
+	m.columnWidths = m.guessColumnWidths(buf, header)
 }
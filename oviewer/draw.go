@@ -41,6 +41,10 @@ func (root *Root) draw() {
 	m.bottomLN = max(lN, 0)
 	m.bottomLX = lX
 
+	if root.input.Event.Mode() == ColumnBoundary {
+		root.drawColumnRuler(m.statusPos - 1)
+	}
+
 	if root.mouseSelect {
 		root.drawSelect(root.x1, root.y1, root.x2, root.y2, true)
 	}
@@ -79,6 +83,9 @@ func (root *Root) drawHeader() int {
 		if root.Doc.LineNumMode {
 			root.blankLineNumber(y)
 		}
+		if root.Doc.TimeDelta {
+			root.blankTimeDelta(y)
+		}
 
 		nextX, nextY := root.drawLine(y, lX, lN, line.lc)
 		// header style
@@ -125,6 +132,13 @@ func (root *Root) drawBody(lX int, lN int) (int, int) {
 				root.blankLineNumber(y)
 			}
 		}
+		if root.Doc.TimeDelta {
+			if valid && wrapNum == 0 {
+				root.drawTimeDelta(lN, y)
+			} else {
+				root.blankTimeDelta(y)
+			}
+		}
 
 		nextX, nextY := root.drawLine(y, lX, lN, line.lc)
 
@@ -159,6 +173,9 @@ func (root *Root) styleContent(lN int, line LineC) {
 		root.columnHighlight(line)
 	}
 	root.multiColorHighlight(line)
+	if root.Doc.WatchMode && root.Doc.WatchDiff {
+		root.watchDiffHighlight(lN, line)
+	}
 	root.searchHighlight(lN, line)
 }
 
@@ -210,9 +227,18 @@ func (root *Root) drawWrapLine(y int, lX int, lN int, lc contents) (int, int) {
 }
 
 // drawNoWrapLine draws contents without wrapping and returns the next drawing position.
+// The header columns are drawn at the left edge without scrolling.
 func (root *Root) drawNoWrapLine(y int, startX int, lN int, lc contents) (int, int) {
 	startX = max(startX, root.minStartX)
-	for x := 0; root.scr.startX+x < root.scr.vWidth; x++ {
+	x := 0
+	if startX > 0 {
+		hx := root.headerColumnX(lN)
+		for ; x < hx && x < len(lc); x++ {
+			content := lc[x]
+			root.Screen.SetContent(root.scr.startX+x, y, content.mainc, content.combc, content.style)
+		}
+	}
+	for ; root.scr.startX+x < root.scr.vWidth; x++ {
 		if startX+x >= len(lc) {
 			// EOL
 			root.clearEOL(root.scr.startX+x, y)
@@ -229,6 +255,24 @@ func (root *Root) drawNoWrapLine(y int, startX int, lN int, lc contents) (int, i
 	return startX, lN
 }
 
+// headerColumnX returns the width of the header columns of the line to draw.
+// Returns 0 if the header columns do not fit on the screen.
+func (root *Root) headerColumnX(lN int) int {
+	m := root.Doc
+	if !m.ColumnMode || m.HeaderColumn <= 0 {
+		return 0
+	}
+	line, valid := m.getLineC(lN, m.TabWidth)
+	if !valid {
+		return 0
+	}
+	hx := m.headerColumnX(line)
+	if hx >= root.scr.vWidth-root.scr.startX {
+		return 0
+	}
+	return hx
+}
+
 // bodyStyle applies the style from the beginning to the end of one line of the body.
 // Apply style to contents.
 func (root *Root) bodyStyle(lc contents, s OVStyle) {
@@ -270,7 +314,7 @@ func (root *Root) multiColorHighlight(line LineC) {
 // blankLineNumber should be blank for the line number.
 func (root *Root) blankLineNumber(y int) {
 	m := root.Doc
-	numC := StrToContents(strings.Repeat(" ", root.scr.startX-1), m.TabWidth)
+	numC := StrToContents(strings.Repeat(" ", root.scr.timeX-1), m.TabWidth)
 	root.setContentString(0, y, numC)
 }
 
@@ -278,7 +322,7 @@ func (root *Root) blankLineNumber(y int) {
 func (root *Root) drawLineNumber(lN int, y int) {
 	m := root.Doc
 	// Line numbers start at 1 except for skip and header lines.
-	numC := StrToContents(fmt.Sprintf("%*d", root.scr.startX-1, lN-m.firstLine()+1), m.TabWidth)
+	numC := StrToContents(fmt.Sprintf("%*d", root.scr.timeX-1, m.originLN(lN)-m.firstLine()+1), m.TabWidth)
 	for i := 0; i < len(numC); i++ {
 		numC[i].style = applyStyle(tcell.StyleDefault, root.StyleLineNumber)
 	}
@@ -341,32 +385,14 @@ func (root *Root) columnDelimiterHighlight(line LineC) {
 
 func (root *Root) columnWidthHighlight(line LineC) {
 	m := root.Doc
-	indexes := m.columnWidths
-	if len(indexes) == 0 {
-		return
-	}
-	iStart, iEnd := 0, 0
 	numC := len(root.StyleColumnRainbow)
-	for c := 0; c < len(indexes)+1; c++ {
-		switch {
-		case c == 0:
-			iStart = 0
-			iEnd = findBounds(line.lc, indexes[0]-1, indexes, c)
-		case c < len(indexes):
-			iStart = iEnd + 1
-			iEnd = findBounds(line.lc, indexes[c], indexes, c)
-		case c == len(indexes):
-			iStart = iEnd + 1
-			iEnd = len(line.str)
-		}
-		iEnd = min(iEnd, len(line.lc))
-
+	for c, bound := range columnWidthBounds(line.lc, m.columnWidths) {
 		if m.ColumnRainbow {
-			RangeStyle(line.lc, iStart, iEnd, root.StyleColumnRainbow[c%numC])
+			RangeStyle(line.lc, bound[0], bound[1], root.StyleColumnRainbow[c%numC])
 		}
 
 		if c == m.columnCursor {
-			RangeStyle(line.lc, iStart, iEnd, root.StyleColumnHighlight)
+			RangeStyle(line.lc, bound[0], bound[1], root.StyleColumnHighlight)
 		}
 	}
 }
@@ -494,8 +520,21 @@ func (root *Root) normalLeftStatus() (contents, int) {
 	} else if root.Config.Prompt.Normal.ShowFilename {
 		caption = root.Doc.FileName
 	}
+	if root.Doc.CFormat != UNCOMPRESSED {
+		caption += "(" + root.Doc.CFormat.String() + ")"
+	}
+	if root.Doc.Encoding != "" && root.Doc.Encoding != encodingUTF8 {
+		caption += "(" + root.Doc.Encoding + ")"
+	}
+
+	columnName := ""
+	if root.Doc.ColumnMode && root.Doc.ColumnName {
+		if name, ok := root.Doc.columnName(root.Doc.columnCursor); ok {
+			columnName = fmt.Sprintf("[%d:%s]", root.Doc.columnCursor, name)
+		}
+	}
 
-	leftStatus := fmt.Sprintf("%s%s%s:%s", number, modeStatus, caption, root.message)
+	leftStatus := fmt.Sprintf("%s%s%s%s:%s", number, modeStatus, caption, columnName, root.message)
 	leftContents := StrToContents(leftStatus, -1)
 
 	if root.Config.Prompt.Normal.InvertColor {
@@ -0,0 +1,526 @@
+package oviewer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Character encoding names.
+const (
+	encodingUTF8    = "UTF-8"
+	encodingUTF16LE = "UTF-16LE"
+	encodingUTF16BE = "UTF-16BE"
+	encodingSJIS    = "Shift_JIS"
+	encodingEUCJP   = "EUC-JP"
+	encodingLatin1  = "ISO-8859-1"
+)
+
+const (
+	// encodingSniffSize is the size of the beginning of the data used to detect the encoding.
+	encodingSniffSize = 4096
+	// decodeSeekInterval is the interval of the decoded data to record the seek point.
+	decodeSeekInterval = 64 * 1024
+	// decodeBufSize is the size of the buffer to read the encoded data.
+	decodeBufSize = 4096
+)
+
+// encodingAliases is the names that can be specified for each encoding.
+var encodingAliases = map[string]string{
+	"utf-8":       encodingUTF8,
+	"utf8":        encodingUTF8,
+	"utf-16le":    encodingUTF16LE,
+	"utf16le":     encodingUTF16LE,
+	"utf-16be":    encodingUTF16BE,
+	"utf16be":     encodingUTF16BE,
+	"shift_jis":   encodingSJIS,
+	"shift-jis":   encodingSJIS,
+	"sjis":        encodingSJIS,
+	"cp932":       encodingSJIS,
+	"windows-31j": encodingSJIS,
+	"euc-jp":      encodingEUCJP,
+	"eucjp":       encodingEUCJP,
+	"latin1":      encodingLatin1,
+	"latin-1":     encodingLatin1,
+	"iso-8859-1":  encodingLatin1,
+	"iso8859-1":   encodingLatin1,
+}
+
+// ParseEncoding returns the name of the character encoding.
+// "auto" and empty return an empty string, which means detecting the encoding.
+func ParseEncoding(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		return "", nil
+	}
+	if enc, ok := encodingAliases[name]; ok {
+		return enc, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+}
+
+// EncodingNames returns the names of the supported encodings.
+func EncodingNames() []string {
+	return []string{"auto", "utf-8", "utf-16le", "utf-16be", "shift_jis", "euc-jp", "latin1"}
+}
+
+// textEncoding returns the encoding of the name.
+// UTF-8 returns nil because it does not need to be decoded.
+func textEncoding(name string) encoding.Encoding {
+	switch name {
+	case encodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case encodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case encodingSJIS:
+		return japanese.ShiftJIS
+	case encodingEUCJP:
+		return japanese.EUCJP
+	case encodingLatin1:
+		return charmap.ISO8859_1
+	}
+	return nil
+}
+
+// bomEncoding returns the encoding and the length of the BOM.
+func bomEncoding(header []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xef, 0xbb, 0xbf}):
+		return encodingUTF8, 3
+	case bytes.HasPrefix(header, []byte{0xff, 0xfe}):
+		return encodingUTF16LE, 2
+	case bytes.HasPrefix(header, []byte{0xfe, 0xff}):
+		return encodingUTF16BE, 2
+	}
+	return "", 0
+}
+
+// detectEncoding returns the encoding detected from the beginning of the data
+// and the length of the BOM.
+func detectEncoding(header []byte) (string, int) {
+	if enc, bom := bomEncoding(header); enc != "" {
+		return enc, bom
+	}
+	if enc := utf16Encoding(header); enc != "" {
+		return enc, 0
+	}
+	// Binary data is displayed as it is.
+	if likelyUTF8(header) || isBinary(header) {
+		return encodingUTF8, 0
+	}
+	// Most EUC-JP text is also valid as Shift_JIS, so EUC-JP is checked first.
+	// Japanese encodings are chosen only if Japanese characters are found,
+	// otherwise Latin-1 text may also be valid as them.
+	if likelyEUCJP(header) {
+		return encodingEUCJP, 0
+	}
+	if likelySJIS(header) {
+		return encodingSJIS, 0
+	}
+	return encodingLatin1, 0
+}
+
+// utf16Encoding returns UTF-16LE or UTF-16BE if the data looks like UTF-16 without BOM.
+// ASCII characters in UTF-16 have a NUL byte on one side.
+func utf16Encoding(header []byte) string {
+	n := len(header) &^ 1
+	if n < 4 {
+		return ""
+	}
+	even, odd := 0, 0
+	for i := 0; i < n; i += 2 {
+		if header[i] == 0 {
+			even++
+		}
+		if header[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := n / 2
+	switch {
+	case odd*10 > pairs*4 && even*10 < pairs:
+		return encodingUTF16LE
+	case even*10 > pairs*4 && odd*10 < pairs:
+		return encodingUTF16BE
+	}
+	return ""
+}
+
+// maxInvalidUTF8Ratio is the maximum ratio (1/n) of invalid bytes
+// in the data that is still regarded as UTF-8.
+const maxInvalidUTF8Ratio = 100
+
+// likelyUTF8 returns true if the data is UTF-8.
+// An incomplete character at the end is ignored, because the data may be cut off.
+// A few invalid bytes (such as a broken character in a log) are allowed
+// if the data contains valid multibyte characters.
+func likelyUTF8(data []byte) bool {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				data = data[:i]
+			}
+			break
+		}
+	}
+	if utf8.Valid(data) {
+		return true
+	}
+	invalid, multibyte := 0, 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			invalid++
+		case size > 1:
+			multibyte++
+		}
+		i += size
+	}
+	return multibyte > 0 && invalid*maxInvalidUTF8Ratio <= len(data)
+}
+
+// likelySJIS returns true if the data is valid Shift_JIS
+// and contains enough Japanese characters.
+func likelySJIS(data []byte) bool {
+	japanese, other, ok := scoreSJIS(data)
+	return ok && japanese > 0 && japanese*2 >= other
+}
+
+// likelyEUCJP returns true if the data is valid EUC-JP
+// and contains enough Japanese characters.
+func likelyEUCJP(data []byte) bool {
+	japanese, other, ok := scoreEUCJP(data)
+	return ok && japanese > 0 && japanese*2 >= other
+}
+
+// scoreSJIS returns the number of the Japanese characters
+// and the number of the other non-ASCII characters in Shift_JIS.
+// The characters whose lead byte is 0x81-0x9f (symbols, kana and the first level kanji)
+// are counted as Japanese, because the bytes are control characters in Latin-1.
+// Latin-1 letters are also valid as the lead byte of 0xe0-0xfc or half-width katakana,
+// so they are counted as the others.
+// ok is false if the data is not valid Shift_JIS.
+func scoreSJIS(data []byte) (japanese int, other int, ok bool) {
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c < 0x80:
+		case c >= 0xa1 && c <= 0xdf:
+			// Half-width katakana.
+			other++
+		case (c >= 0x81 && c <= 0x9f) || (c >= 0xe0 && c <= 0xfc):
+			i++
+			if i == len(data) {
+				return japanese, other, true
+			}
+			t := data[i]
+			if t < 0x40 || t == 0x7f || t > 0xfc {
+				return japanese, other, false
+			}
+			if c <= 0x9f {
+				japanese++
+			} else {
+				other++
+			}
+		default:
+			return japanese, other, false
+		}
+	}
+	return japanese, other, true
+}
+
+// scoreEUCJP returns the number of the Japanese characters (JIS X 0208)
+// and the number of the other non-ASCII characters in EUC-JP.
+// ok is false if the data is not valid EUC-JP.
+func scoreEUCJP(data []byte) (japanese int, other int, ok bool) {
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		trail := 0
+		switch {
+		case c < 0x80:
+			continue
+		case c == 0x8e:
+			// Half-width katakana.
+			if i+1 < len(data) && (data[i+1] < 0xa1 || data[i+1] > 0xdf) {
+				return japanese, other, false
+			}
+			i++
+			other++
+			continue
+		case c == 0x8f:
+			// JIS X 0212.
+			trail = 2
+			other++
+		case c >= 0xa1 && c <= 0xfe:
+			trail = 1
+			japanese++
+		default:
+			return japanese, other, false
+		}
+		for ; trail > 0 && i+1 < len(data); trail-- {
+			i++
+			if data[i] < 0xa1 || data[i] > 0xfe {
+				return japanese, other, false
+			}
+		}
+	}
+	return japanese, other, true
+}
+
+// decodeSeeker is an io.ReadSeeker that decodes the text into UTF-8.
+// The offset of Seek is the offset of the decoded data,
+// so the start position of chunks is the same as that of UTF-8 files.
+// Seek points are recorded at intervals of the decoded data,
+// and Seek decodes again from the nearest point.
+type decodeSeeker struct {
+	// r is the encoded data.
+	r io.Reader
+	// rs is the encoded data if it is seekable.
+	rs io.ReadSeeker
+	t  transform.Transformer
+	// src is the encoded data that has not been decoded yet.
+	src []byte
+	// dst is the decoded data that has not been read yet.
+	dst    []byte
+	srcBuf []byte
+	dstBuf []byte
+	points []seekPoint
+	// pos is the offset of the decoded data that has been read.
+	pos int64
+	// rawPos is the offset of the encoded data that has been decoded.
+	rawPos int64
+	// size is the size of the encoded data when EOF is reached.
+	size int64
+}
+
+// newDecodeSeeker returns a decodeSeeker.
+// rs is nil if the data is not seekable.
+// The data starts at bom, skipping the BOM.
+func newDecodeSeeker(r io.Reader, rs io.ReadSeeker, enc encoding.Encoding, bom int64) *decodeSeeker {
+	var t transform.Transformer = transform.Nop
+	if enc != nil {
+		t = enc.NewDecoder()
+	}
+	return &decodeSeeker{
+		r:      r,
+		rs:     rs,
+		t:      t,
+		srcBuf: make([]byte, decodeBufSize),
+		dstBuf: make([]byte, decodeBufSize*3),
+		points: []seekPoint{{offset: 0, cOffset: bom}},
+		rawPos: bom,
+	}
+}
+
+// Read reads the decoded data.
+// Read fills p unless EOF is reached, as well as a file.
+func (d *decodeSeeker) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.dst) == 0 {
+			if err := d.decode(); err != nil {
+				if n > 0 && errors.Is(err, io.EOF) {
+					return n, nil
+				}
+				return n, err
+			}
+			continue
+		}
+		c := copy(p[n:], d.dst)
+		d.dst = d.dst[c:]
+		d.pos += int64(c)
+		n += c
+	}
+	return n, nil
+}
+
+// decode decodes the next encoded data into dst.
+func (d *decodeSeeker) decode() error {
+	atEOF := false
+	if len(d.src) < decodeBufSize/2 {
+		l := copy(d.srcBuf, d.src)
+		n, err := io.ReadFull(d.r, d.srcBuf[l:])
+		d.src = d.srcBuf[:l+n]
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return err
+			}
+			atEOF = true
+		}
+	}
+	nDst, nSrc, err := d.t.Transform(d.dstBuf, d.src, atEOF)
+	if err != nil && !errors.Is(err, transform.ErrShortDst) && !errors.Is(err, transform.ErrShortSrc) {
+		return err
+	}
+	d.dst = d.dstBuf[:nDst]
+	d.src = d.src[nSrc:]
+	d.rawPos += int64(nSrc)
+	d.addPoint()
+	if nDst == 0 && atEOF {
+		d.size = d.rawPos + int64(len(d.src))
+		return io.EOF
+	}
+	return nil
+}
+
+// addPoint records the seek point if the interval has passed.
+// The decoder has no pending state after Transform,
+// so decoding can be restarted from the point.
+func (d *decodeSeeker) addPoint() {
+	if len(d.src) != 0 {
+		return
+	}
+	offset := d.pos + int64(len(d.dst))
+	last := d.points[len(d.points)-1]
+	if offset-last.offset < decodeSeekInterval {
+		return
+	}
+	d.points = append(d.points, seekPoint{offset: offset, cOffset: d.rawPos})
+}
+
+// Seek sets the offset of the decoded data.
+// io.SeekEnd is not supported, because the size of the decoded data is unknown.
+func (d *decodeSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += d.pos
+	default:
+		return d.pos, ErrSeekEncoding
+	}
+	if offset == d.pos {
+		return d.pos, nil
+	}
+	if d.rs == nil {
+		return d.pos, ErrSeekEncoding
+	}
+	if offset < d.pos || offset-d.pos > decodeSeekInterval {
+		if err := d.restart(offset); err != nil {
+			return d.pos, err
+		}
+	}
+	if _, err := io.CopyN(io.Discard, d, offset-d.pos); err != nil && !errors.Is(err, io.EOF) {
+		return d.pos, err
+	}
+	return d.pos, nil
+}
+
+// restart restarts decoding from the nearest seek point before offset.
+func (d *decodeSeeker) restart(offset int64) error {
+	i := sort.Search(len(d.points), func(i int) bool {
+		return d.points[i].offset > offset
+	}) - 1
+	p := d.points[max(i, 0)]
+	if _, err := d.rs.Seek(p.cOffset, io.SeekStart); err != nil {
+		return err
+	}
+	d.t.Reset()
+	d.src = nil
+	d.dst = nil
+	d.pos = p.offset
+	d.rawPos = p.cOffset
+	return nil
+}
+
+// readHeader reads the beginning of the data to detect the encoding.
+func readHeader(r io.Reader) ([]byte, error) {
+	header := make([]byte, encodingSniffSize)
+	n, err := io.ReadAtLeast(r, header, 7)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// headerEncoding returns the encoding and the length of the BOM.
+// If name is specified, it is used instead of detecting.
+// The BOM is skipped even if the encoding is specified.
+func headerEncoding(header []byte, name string) (string, int) {
+	if name == "" {
+		return detectEncoding(header)
+	}
+	if enc, bom := bomEncoding(header); enc == name {
+		return enc, bom
+	}
+	return name, 0
+}
+
+// decodedReader returns a reader that decodes the non-seekable data into UTF-8.
+// It returns the reader as it is if the data is UTF-8 without BOM.
+func decodedReader(r io.Reader, name string) (string, io.Reader) {
+	header, err := readHeader(r)
+	if err != nil {
+		return encodingUTF8, r
+	}
+	enc, bom := headerEncoding(header, name)
+	mr := io.MultiReader(bytes.NewReader(header), r)
+	if enc == encodingUTF8 && bom == 0 {
+		return enc, mr
+	}
+	return enc, newDecodeSeeker(io.MultiReader(bytes.NewReader(header[bom:]), r), nil, textEncoding(enc), int64(bom))
+}
+
+// decodedSeeker returns a seeker that decodes the seekable data into UTF-8.
+// It returns nil if the data is UTF-8 without BOM.
+func decodedSeeker(rs io.ReadSeeker, name string) (string, *decodeSeeker, error) {
+	header, err := readHeader(rs)
+	if err != nil {
+		return encodingUTF8, nil, err
+	}
+	enc, bom := headerEncoding(header, name)
+	if _, err := rs.Seek(int64(bom), io.SeekStart); err != nil {
+		return enc, nil, err
+	}
+	if enc == encodingUTF8 && bom == 0 {
+		return enc, nil, nil
+	}
+	return enc, newDecodeSeeker(rs, rs, textEncoding(enc), int64(bom)), nil
+}
+
+// encodingName returns the encoding specified for the document.
+func (m *Document) encodingName() string {
+	if m.specEncoding != "" {
+		return m.specEncoding
+	}
+	enc, err := ParseEncoding(Encoding)
+	if err != nil {
+		return ""
+	}
+	return enc
+}
+
+// decoded returns true if the document is read through the decoder.
+func (m *Document) decoded() bool {
+	_, ok := m.seeker.(*decodeSeeker)
+	return ok
+}
+
+// setEncoding sets the encoding of the current document and reloads it.
+func (root *Root) setEncoding(input string) {
+	enc, err := ParseEncoding(input)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	m := root.Doc
+	m.specEncoding = enc
+	if err := m.reload(); err != nil {
+		root.setMessagef("encoding: %s", err)
+		return
+	}
+	if enc == "" {
+		enc = "auto"
+	}
+	root.setMessagef("Set encoding %s", enc)
+}
@@ -0,0 +1,194 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encodeTestData encodes the UTF-8 string in the encoding.
+func encodeTestData(t *testing.T, enc encoding.Encoding, str string) []byte {
+	t.Helper()
+	b, err := enc.NewEncoder().Bytes([]byte(str))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// encodingTestLines returns lines containing Japanese.
+func encodingTestLines(start, end int) string {
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		fmt.Fprintf(&b, "%d 行目のテキスト\n", i)
+	}
+	return b.String()
+}
+
+func Test_detectEncoding(t *testing.T) {
+	t.Parallel()
+	text := "日本語のテキスト\n"
+	tests := []struct {
+		name    string
+		header  []byte
+		want    string
+		wantBOM int
+	}{
+		{name: "utf-8", header: []byte(text), want: encodingUTF8},
+		{name: "utf-8 bom", header: append([]byte{0xef, 0xbb, 0xbf}, text...), want: encodingUTF8, wantBOM: 3},
+		{name: "utf-8 cut off", header: []byte(text)[:4], want: encodingUTF8},
+		{name: "utf-8 cut off 4 bytes", header: []byte(strings.Repeat(text, 10) + "\xf0\x9f\x98"), want: encodingUTF8},
+		{name: "utf-8 invalid byte", header: []byte(strings.Repeat(text, 10) + "\xff" + text), want: encodingUTF8},
+		{name: "utf-8 many invalid bytes", header: []byte(text + "\xe9\xe8\xe0\n"), want: encodingLatin1},
+		{name: "utf-16le bom", header: []byte{0xff, 0xfe, 'a', 0}, want: encodingUTF16LE, wantBOM: 2},
+		{name: "utf-16be bom", header: []byte{0xfe, 0xff, 0, 'a'}, want: encodingUTF16BE, wantBOM: 2},
+		{name: "utf-16le", header: encodeTestData(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "abc,def\n"), want: encodingUTF16LE},
+		{name: "utf-16be", header: encodeTestData(t, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "abc,def\n"), want: encodingUTF16BE},
+		{name: "shift_jis", header: encodeTestData(t, japanese.ShiftJIS, text), want: encodingSJIS},
+		{name: "euc-jp", header: encodeTestData(t, japanese.EUCJP, text), want: encodingEUCJP},
+		{name: "latin1", header: []byte("caf\xe9 cr\xe8me\n"), want: encodingLatin1},
+		{name: "latin1 valid as shift_jis", header: []byte("se\xf1or ni\xf1o\n"), want: encodingLatin1},
+		{name: "latin1 umlaut", header: []byte("M\xfcller\n"), want: encodingLatin1},
+		{name: "latin1 accented words", header: []byte("d\xe9cembre \xe0 ni\xf1o \xc0\xc9\n"), want: encodingLatin1},
+		{name: "shift_jis half-width katakana only", header: []byte("\xb1\xb2\xb3\n"), want: encodingLatin1},
+		{name: "shift_jis kanji", header: encodeTestData(t, japanese.ShiftJIS, "漢字の表示\n"), want: encodingSJIS},
+		{name: "euc-jp kana", header: encodeTestData(t, japanese.EUCJP, "ひらがなカタカナ\n"), want: encodingEUCJP},
+		{name: "binary", header: []byte{0x7f, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0, 0xff, 0x80}, want: encodingUTF8},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, bom := detectEncoding(tt.header)
+			if got != tt.want {
+				t.Errorf("detectEncoding() = %v, want %v", got, tt.want)
+			}
+			if bom != tt.wantBOM {
+				t.Errorf("detectEncoding() bom = %v, want %v", bom, tt.wantBOM)
+			}
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: ""},
+		{name: "auto", want: ""},
+		{name: "SJIS", want: encodingSJIS},
+		{name: "euc-jp", want: encodingEUCJP},
+		{name: "utf-16le", want: encodingUTF16LE},
+		{name: "latin1", want: encodingLatin1},
+		{name: "ebcdic", want: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseEncoding(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEncoding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeSeeker(t *testing.T) {
+	t.Parallel()
+	want := []byte(encodingTestLines(0, 20000))
+	tests := []struct {
+		name string
+		enc  string
+		data []byte
+	}{
+		{name: "shift_jis", enc: encodingSJIS, data: encodeTestData(t, japanese.ShiftJIS, string(want))},
+		{name: "utf-16le bom", enc: "", data: append([]byte{0xff, 0xfe}, encodeTestData(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), string(want))...)},
+		{name: "utf-8 bom", enc: "", data: append([]byte{0xef, 0xbb, 0xbf}, want...)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, ds, err := decodedSeeker(bytes.NewReader(tt.data), tt.enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(ds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("ReadAll() length = %d, want %d", len(got), len(want))
+			}
+			for _, off := range []int64{300000, 10, 200000, 200100, int64(len(want)) - 5} {
+				if _, err := ds.Seek(off, io.SeekStart); err != nil {
+					t.Fatal(err)
+				}
+				buf := make([]byte, 5)
+				if _, err := io.ReadFull(ds, buf); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(buf, want[off:off+5]) {
+					t.Errorf("Seek(%d) = %q, want %q", off, buf, want[off:off+5])
+				}
+			}
+			if _, err := ds.Seek(0, io.SeekEnd); err == nil {
+				t.Errorf("Seek(SeekEnd) error = nil, want error")
+			}
+		})
+	}
+}
+
+func Test_decodedReader(t *testing.T) {
+	t.Parallel()
+	want := encodingTestLines(0, 100)
+	data := encodeTestData(t, japanese.EUCJP, want)
+	enc, r := decodedReader(bytes.NewReader(data), "")
+	if enc != encodingEUCJP {
+		t.Errorf("decodedReader() encoding = %v, want %v", enc, encodingEUCJP)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("decodedReader() = %q, want %q", got[:20], want[:20])
+	}
+}
+
+func TestDocument_encoding(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "sjis.txt")
+	data := encodeTestData(t, japanese.ShiftJIS, encodingTestLines(0, 25000))
+	if err := os.WriteFile(fileName, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	if m.Encoding != encodingSJIS {
+		t.Errorf("Encoding = %v, want %v", m.Encoding, encodingSJIS)
+	}
+	if got := m.BufEndNum(); got != 25000 {
+		t.Fatalf("BufEndNum() = %d, want 25000", got)
+	}
+	for _, lN := range []int{0, 24999, 10000, 19999, 12345} {
+		want := fmt.Sprintf("%d 行目のテキスト", lN)
+		if got := indexTestLine(t, m, lN); got != want {
+			t.Errorf("line %d = %q, want %q", lN, got, want)
+		}
+	}
+}
@@ -42,7 +42,11 @@ func (root *Root) eventLoop(ctx context.Context, quitChan chan<- struct{}) {
 		case *eventAddDocument:
 			root.addDocument(ev.m)
 		case *eventCloseDocument:
-			root.closeDocument()
+			if ev.m != nil {
+				root.closeDocumentOf(ev.m)
+			} else {
+				root.closeDocument()
+			}
 		case *eventCopySelect:
 			root.putClipboard(ctx)
 		case *eventPaste:
@@ -60,7 +64,7 @@ func (root *Root) eventLoop(ctx context.Context, quitChan chan<- struct{}) {
 		case *eventSearchMove:
 			root.searchGo(ev.value)
 		case *eventGoto:
-			root.goLine(ev.value)
+			root.goLine(ctx, ev.value)
 		case *eventHeader:
 			root.setHeader(ev.value)
 		case *eventSkipLines:
@@ -83,6 +87,26 @@ func (root *Root) eventLoop(ctx context.Context, quitChan chan<- struct{}) {
 			root.setJumpTarget(ev.value)
 		case *eventSaveBuffer:
 			root.saveBuffer(ev.value)
+		case *eventSortColumn:
+			root.sortColumn(ctx, ev.value)
+		case *eventColumnStats:
+			root.columnStats(ctx)
+		case *eventColumnBoundary:
+			root.setColumnBoundary(ev.value)
+		case *eventEncoding:
+			root.setEncoding(ev.value)
+		case *eventRecordSeparator:
+			root.setRecordSeparator(ev.value)
+		case *eventSignal:
+			root.sendSignal(ev.value)
+		case *eventStdin:
+			root.sendStdin(ev.value)
+		case *eventSetBookmark:
+			root.setBookmarkCurrent(ev.value)
+		case *eventGoBookmark:
+			root.goBookmark(ev.value)
+		case *eventCommandStatus:
+			ev.cmd.updateStatus(ev.run)
 
 		// tcell events
 		case *tcell.EventResize:
@@ -119,6 +143,8 @@ func (root *Root) everyUpdate() {
 	}
 	root.skipDraw = false
 
+	root.notifyBinary()
+
 	if atomic.SwapInt32(&root.Doc.watchRestart, 0) == 1 {
 		root.watchControl()
 	}
@@ -266,6 +292,7 @@ func (root *Root) sendAddDocument(m *Document) {
 
 // eventCloseDocument represents a close document event.
 type eventCloseDocument struct {
+	m *Document
 	tcell.EventTime
 }
 
@@ -283,6 +310,17 @@ func (root *Root) sendCloseDocument() {
 	root.postEvent(ev)
 }
 
+// sendCloseDocumentOf fires the eventCloseDocument event that closes the specified document.
+func (root *Root) sendCloseDocumentOf(m *Document) {
+	if !root.checkScreen() {
+		return
+	}
+	ev := &eventCloseDocument{}
+	ev.m = m
+	ev.SetEventNow()
+	root.postEvent(ev)
+}
+
 // eventReload represents a reload event.
 type eventReload struct {
 	m *Document
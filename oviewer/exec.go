@@ -8,8 +8,11 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"golang.org/x/term"
 )
 
@@ -21,6 +24,66 @@ type Command struct {
 	docout  *Document
 	docerr  *Document
 	args    []string
+
+	// StopOnError stops watch mode when the command exits with a non-zero status.
+	StopOnError bool
+	// Pty runs the command under a pty (linux only).
+	// stdout and stderr are displayed together in one document.
+	Pty bool
+	// Combined adds the document that interleaves stdout and stderr in arrival order.
+	Combined bool
+	// Stdin connects the stdin of the command to a pipe to write lines with WriteInput.
+	// Otherwise, the stdin of the command is null device if the stdin of ov is a terminal.
+	Stdin bool
+
+	combined *combinedDocument
+
+	// width and height are the terminal size of the pty.
+	width  int
+	height int
+
+	mu  sync.Mutex
+	run *commandRun
+	// root receives the status of the command in the event loop.
+	root *Root
+}
+
+// commandRun is the status of one execution of the command.
+type commandRun struct {
+	command *exec.Cmd
+	start   time.Time
+	// pty is the master of the pty if the command runs under a pty.
+	pty *os.File
+	// stdin is the stdin of the command if Stdin is true and the stdin of ov is a terminal.
+	stdin io.WriteCloser
+	// input is the data written to the stdin (or the pty) by writeInput.
+	input chan []byte
+	// inputClosed is true if the stdin has been requested to be closed.
+	inputClosed bool
+	// signal is the name of the last signal sent to the command.
+	signal string
+	// open is the number of the pipes that have not reached EOF.
+	open     int32
+	killed   int32
+	once     sync.Once
+	done     chan struct{}
+	exitCode int
+	elapsed  time.Duration
+}
+
+// exitReader calls eof once when the reader returns an error.
+type exitReader struct {
+	r    io.Reader
+	eof  func()
+	once sync.Once
+}
+
+func (r *exitReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil {
+		r.once.Do(r.eof)
+	}
+	return n, err
 }
 
 // NewCommand return the structure of Command.
@@ -38,17 +101,44 @@ func (cmd *Command) Exec() (*Root, error) {
 	}
 	cmd.docout = docout
 	cmd.docerr = docerr
+	if cmd.Combined && !cmd.Pty {
+		cd, err := newCombinedDocument()
+		if err != nil {
+			return nil, err
+		}
+		cd.doc.command = cmd
+		cd.doc.store.formfeedTime = true
+		cmd.combined = cd
+	}
+
+	// The pty is started with the size of the screen.
+	var root *Root
+	if cmd.Pty {
+		cmd.docout.FileName = "PTY"
+		// The pty terminates lines with CRLF.
+		cmd.docout.store.setSeparator('\n', true)
+		root, err = NewOviewer(cmd.docout)
+		if err != nil {
+			return nil, err
+		}
+		root.prepareView()
+		cmd.width, cmd.height = root.scr.vWidth, root.scr.vHeight-statusLine
+		// stdout and stderr are not separated under a pty.
+		if cmd.Combined {
+			root.message = "--combined is ignored with --pty"
+			log.Print(root.message)
+		}
+	}
 
-	cmd.command = exec.Command(cmd.args[0], cmd.args[1:]...)
-	so, se, err := commandStart(cmd.command)
+	so, se, err := cmd.start()
 	if err != nil {
+		if root != nil {
+			root.Close()
+		}
 		return nil, err
 	}
-	cmd.stdout = so
-	cmd.stderr = se
-
-	cmd.docout.Caption = "(" + cmd.command.Args[0] + ")" + cmd.docout.FileName
-	cmd.docerr.Caption = "(" + cmd.command.Args[0] + ")" + cmd.docerr.FileName
+	cmd.docout.command = cmd
+	cmd.docerr.command = cmd
 	atomic.StoreInt32(&cmd.docout.closed, 0)
 	atomic.StoreInt32(&cmd.docerr.closed, 0)
 	cmd.docout.seekable = false
@@ -59,10 +149,27 @@ func (cmd *Command) Exec() (*Root, error) {
 	if err = cmd.docout.ControlReader(so, cmd.Reload); err != nil {
 		log.Printf("%s", err)
 	}
-	if err = cmd.docerr.ControlReader(se, cmd.stderrReload); err != nil {
-		log.Printf("%s", err)
+	if !cmd.Pty {
+		if err = cmd.docerr.ControlReader(se, cmd.stderrReload); err != nil {
+			log.Printf("%s", err)
+		}
+		if cmd.combined != nil {
+			root, err = NewOviewer(cmd.combined.doc, cmd.docout, cmd.docerr)
+		} else {
+			root, err = NewOviewer(cmd.docout, cmd.docerr)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
-	return NewOviewer(cmd.docout, cmd.docerr)
+
+	cmd.mu.Lock()
+	cmd.root = root
+	run := cmd.run
+	cmd.mu.Unlock()
+	// The event loop has not started yet.
+	cmd.updateStatus(run)
+	return root, nil
 }
 
 // Wait waits for the command to exit.
@@ -74,15 +181,18 @@ func (cmd *Command) Wait() {
 	atomic.StoreInt32(&cmd.docout.closed, 1)
 	atomic.StoreInt32(&cmd.docerr.closed, 1)
 
+	cmd.mu.Lock()
+	run := cmd.run
+	cmd.mu.Unlock()
+	atomic.StoreInt32(&run.killed, 1)
+
 	// Kill the command if it hasn't exited yet.
 	if err := cmd.command.Process.Kill(); err != nil {
 		log.Println(err)
 	}
 
 	// Wait for the command to exit.
-	if err := cmd.command.Wait(); err != nil {
-		log.Println(err)
-	}
+	run.wait()
 }
 
 // Reload restarts the command.
@@ -94,17 +204,20 @@ func (cmd *Command) Reload() *bufio.Reader {
 	} else {
 		cmd.docout.reset()
 	}
-	cmd.command = exec.Command(cmd.args[0], cmd.args[1:]...)
-	so, se, err := commandStart(cmd.command)
+	if cmd.combined != nil {
+		cmd.combined.rerun(cmd.combined.doc.WatchMode)
+	}
+	so, _, err := cmd.start()
 	if err != nil {
 		log.Println(err)
 		str := fmt.Sprintf("command error: %s", err)
 		reader := bufio.NewReader(strings.NewReader(str))
 		return reader
 	}
-	cmd.stdout = so
-	cmd.stderr = se
 
+	if cmd.Pty {
+		return bufio.NewReader(so)
+	}
 	cmd.docerr.requestReload()
 	atomic.StoreInt32(&cmd.docerr.store.readCancel, 0)
 	log.Println("stderr receive done")
@@ -112,6 +225,285 @@ func (cmd *Command) Reload() *bufio.Reader {
 	return bufio.NewReader(so)
 }
 
+// start starts the command and records the start of the execution.
+func (cmd *Command) start() (io.Reader, io.Reader, error) {
+	command := exec.Command(cmd.args[0], cmd.args[1:]...)
+	var so, se io.Reader
+	var pty *os.File
+	var stdin io.WriteCloser
+	var err error
+	// The stdin of ov is passed to the command if it is not a terminal.
+	if cmd.Stdin && !cmd.Pty && term.IsTerminal(int(os.Stdin.Fd())) {
+		if stdin, err = command.StdinPipe(); err != nil {
+			return nil, nil, fmt.Errorf("stdin pipe error: %w", err)
+		}
+	}
+	if cmd.Pty {
+		cmd.mu.Lock()
+		width, height := cmd.width, cmd.height
+		cmd.mu.Unlock()
+		so, pty, err = ptyStart(command, width, height)
+	} else {
+		so, se, err = commandStart(command)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	run := &commandRun{
+		command: command,
+		start:   time.Now(),
+		pty:     pty,
+		stdin:   stdin,
+		open:    2,
+		done:    make(chan struct{}),
+	}
+	if pty != nil {
+		run.open = 1
+	}
+	switch {
+	case pty != nil:
+		run.input = make(chan []byte, inputQueueSize)
+		go run.writeInput(pty, nil)
+	case stdin != nil:
+		run.input = make(chan []byte, inputQueueSize)
+		go run.writeInput(stdin, stdin)
+	}
+	cmd.mu.Lock()
+	cmd.command = command
+	cmd.run = run
+	cmd.mu.Unlock()
+
+	eof := func(num int) func() {
+		return func() {
+			if cmd.combined != nil {
+				cmd.combined.flush(num)
+			}
+			if atomic.AddInt32(&run.open, -1) == 0 {
+				go cmd.finish(run)
+			}
+		}
+	}
+	if cmd.combined != nil {
+		so = io.TeeReader(so, cmd.combined.stream(0))
+		se = io.TeeReader(se, cmd.combined.stream(1))
+	}
+	cmd.stdout = &exitReader{r: so, eof: eof(0)}
+	cmd.stderr = nil
+	if se != nil {
+		cmd.stderr = &exitReader{r: se, eof: eof(1)}
+	}
+	cmd.sendStatus(run)
+	return cmd.stdout, cmd.stderr, nil
+}
+
+// finish waits for the command that has closed stdout and stderr,
+// and sends the exit status to the event loop.
+func (cmd *Command) finish(run *commandRun) {
+	run.wait()
+	if atomic.LoadInt32(&run.killed) == 1 {
+		return
+	}
+	cmd.sendStatus(run)
+}
+
+// eventCommandStatus represents the status update of the command.
+type eventCommandStatus struct {
+	cmd *Command
+	run *commandRun
+	tcell.EventTime
+}
+
+// sendStatus fires the eventCommandStatus event.
+// The status is not sent before the root is created,
+// because Exec displays the status at the end.
+func (cmd *Command) sendStatus(run *commandRun) {
+	cmd.mu.Lock()
+	root := cmd.root
+	cmd.mu.Unlock()
+	if !root.checkScreen() {
+		return
+	}
+	ev := &eventCommandStatus{cmd: cmd, run: run}
+	ev.SetEventNow()
+	root.postEvent(ev)
+}
+
+// updateStatus displays the status of the execution in the caption.
+// Watch mode is stopped if StopOnError is true and the exit status is non-zero.
+// It is called in the event loop, because it changes the documents being displayed.
+func (cmd *Command) updateStatus(run *commandRun) {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	if run == nil || cmd.run != run {
+		return
+	}
+	status := run.status()
+	if run.exited() && atomic.LoadInt32(&run.killed) == 0 &&
+		cmd.StopOnError && run.exitCode != 0 && cmd.docout.WatchMode {
+		cmd.docout.unWatchMode()
+		atomic.StoreInt32(&cmd.docout.watchRestart, 1)
+		status += " watch stopped"
+	}
+	cmd.setCaption(status)
+}
+
+// resize changes the terminal size of the pty.
+func (cmd *Command) resize(width int, height int) {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	if cmd.width == width && cmd.height == height {
+		return
+	}
+	cmd.width, cmd.height = width, height
+	if cmd.run == nil || cmd.run.pty == nil {
+		return
+	}
+	if err := setPtySize(cmd.run.pty, width, height); err != nil {
+		log.Println(err)
+	}
+}
+
+// setCaption sets the caption of stdout and stderr with the status.
+func (cmd *Command) setCaption(status string) {
+	name := "(" + cmd.args[0] + ")"
+	cmd.docout.Caption = name + cmd.docout.FileName + " " + status
+	cmd.docerr.Caption = name + cmd.docerr.FileName + " " + status
+	if cmd.combined != nil {
+		cmd.combined.doc.Caption = name + cmd.combined.doc.FileName + " " + status
+	}
+}
+
+// wait waits for the command to exit and records the exit status.
+func (run *commandRun) wait() {
+	run.once.Do(func() {
+		if err := run.command.Wait(); err != nil {
+			log.Println(err)
+		}
+		run.elapsed = time.Since(run.start)
+		run.exitCode = -1
+		if run.command.ProcessState != nil {
+			run.exitCode = run.command.ProcessState.ExitCode()
+		}
+		if run.pty != nil {
+			run.pty.Close()
+		}
+		close(run.done)
+	})
+}
+
+// status returns the exit status, the elapsed time and the start time of the execution.
+func (run *commandRun) status() string {
+	if !run.exited() {
+		if run.signal != "" {
+			return fmt.Sprintf("[running since %s, sent %s]", run.start.Format("15:04:05"), run.signal)
+		}
+		return fmt.Sprintf("[running since %s]", run.start.Format("15:04:05"))
+	}
+	state := fmt.Sprintf("exit %d", run.exitCode)
+	// The command terminated by a signal has no exit code.
+	if run.exitCode < 0 && run.command.ProcessState != nil {
+		state = run.command.ProcessState.String()
+	}
+	return fmt.Sprintf("[%s %s at %s]", state, run.elapsed.Round(time.Millisecond), run.start.Format("15:04:05"))
+}
+
+// Signal sends the signal to the running command.
+func (cmd *Command) Signal(sig os.Signal) error {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	run := cmd.run
+	if run == nil || run.exited() {
+		return ErrCommandExited
+	}
+	if err := run.command.Process.Signal(sig); err != nil {
+		return fmt.Errorf("signal %s: %w", sig, err)
+	}
+	run.signal = signalName(sig)
+	cmd.setCaption(run.status())
+	return nil
+}
+
+// inputQueueSize is the number of the inputs waiting to be written to the command.
+const inputQueueSize = 64
+
+// WriteInput writes the line to the stdin of the running command.
+// The line is written in the background, so it does not wait for the command to read it.
+func (cmd *Command) WriteInput(str string) error {
+	return cmd.sendInput([]byte(str + "\n"))
+}
+
+// CloseInput closes the stdin of the running command
+// after the lines written before are written.
+// The command under a pty receives EOF (ctrl+d) instead.
+func (cmd *Command) CloseInput() error {
+	cmd.mu.Lock()
+	run := cmd.run
+	pty := run != nil && run.pty != nil
+	cmd.mu.Unlock()
+	if pty {
+		return cmd.sendInput([]byte{0x04})
+	}
+	return cmd.sendInput(nil)
+}
+
+// sendInput queues the data to be written to the stdin of the running command.
+// nil closes the stdin.
+func (cmd *Command) sendInput(data []byte) error {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	run := cmd.run
+	if run == nil || run.exited() {
+		return ErrCommandExited
+	}
+	if run.input == nil || run.inputClosed {
+		return ErrNoStdin
+	}
+	if data == nil {
+		run.inputClosed = true
+		close(run.input)
+		return nil
+	}
+	select {
+	case run.input <- data:
+		return nil
+	default:
+		return ErrStdinBusy
+	}
+}
+
+// writeInput writes the queued data to w until the input is closed or the command exits.
+// closer is closed when the input is closed.
+func (run *commandRun) writeInput(w io.Writer, closer io.Closer) {
+	for {
+		select {
+		case data, ok := <-run.input:
+			if !ok {
+				if closer != nil {
+					if err := closer.Close(); err != nil {
+						log.Printf("close stdin: %s", err)
+					}
+				}
+				return
+			}
+			if _, err := w.Write(data); err != nil {
+				log.Printf("write stdin: %s", err)
+			}
+		case <-run.done:
+			return
+		}
+	}
+}
+
+// exited returns true if the command has exited.
+func (run *commandRun) exited() bool {
+	select {
+	case <-run.done:
+		return true
+	default:
+		return false
+	}
+}
+
 // stderrReload is called when the command is restarted.
 func (cmd *Command) stderrReload() *bufio.Reader {
 	if !cmd.docout.WatchMode {
@@ -157,12 +549,14 @@ func newOutErrDocument() (*Document, *Document, error) {
 		return nil, nil, err
 	}
 	docout.FileName = "STDOUT"
+	docout.setDefaultSeparator()
 
 	docerr, err := NewDocument()
 	if err != nil {
 		return nil, nil, err
 	}
 	docerr.FileName = "STDERR"
+	docerr.setDefaultSeparator()
 
 	return docout, docerr, nil
 }
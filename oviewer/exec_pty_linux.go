@@ -0,0 +1,105 @@
+//go:build linux
+// +build linux
+
+package oviewer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// ptyReader reads the output from the pty.
+// The pty returns EIO when the command exits, so it is treated as EOF.
+type ptyReader struct {
+	f *os.File
+}
+
+func (r ptyReader) Read(p []byte) (int, error) {
+	n, err := r.f.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// ptyStart starts the command with stdin, stdout and stderr connected to a new pty.
+func ptyStart(command *exec.Cmd, width int, height int) (io.Reader, *os.File, error) {
+	master, slave, err := openPty()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer slave.Close()
+	if err := setPtySize(master, width, height); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	command.Stdin = slave
+	command.Stdout = slave
+	command.Stderr = slave
+	command.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := command.Start(); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("command start error: %w", err)
+	}
+
+	var so io.Reader = ptyReader{f: master}
+	if STDOUTPIPE != nil {
+		so = io.TeeReader(so, STDOUTPIPE)
+	}
+	return so, master, nil
+}
+
+// openPty opens the master and the slave of a new pty.
+func openPty() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open pty: %w", err)
+	}
+	var n uint32
+	if err := ioctl(master, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("pty number: %w", err)
+	}
+	var unlock int32
+	if err := ioctl(master, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("unlock pty: %w", err)
+	}
+	slave, err := os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("open pty: %w", err)
+	}
+	return master, slave, nil
+}
+
+// setPtySize sets the terminal size of the pty.
+func setPtySize(f *os.File, width int, height int) error {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	ws := struct {
+		row, col, x, y uint16
+	}{
+		row: uint16(height),
+		col: uint16(width),
+	}
+	if err := ioctl(f, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws))); err != nil {
+		return fmt.Errorf("set pty size: %w", err)
+	}
+	return nil
+}
+
+func ioctl(f *os.File, req uintptr, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package oviewer
+
+import (
+	"io"
+	"os"
+	"os/exec"
+)
+
+// ptyStart is not supported other than linux.
+func ptyStart(command *exec.Cmd, width int, height int) (io.Reader, *os.File, error) {
+	return nil, nil, ErrNotSupportedPty
+}
+
+// Dummy function because pty is not supported other than linux.
+func setPtySize(f *os.File, width int, height int) error {
+	return nil
+}
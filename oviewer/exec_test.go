@@ -1,8 +1,16 @@
 package oviewer
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -100,3 +108,320 @@ func TestCommand_Exec(t *testing.T) {
 		})
 	}
 }
+
+// waitCommandExit processes the status events of the command in place of the event loop
+// until the status after the exit is displayed.
+func waitCommandExit(t *testing.T, root *Root) {
+	t.Helper()
+	timer := time.AfterFunc(5*time.Second, func() {
+		root.postEvent(&eventAppQuit{})
+	})
+	defer timer.Stop()
+	for {
+		switch ev := root.Screen.PollEvent().(type) {
+		case *eventCommandStatus:
+			ev.cmd.updateStatus(ev.run)
+			if ev.run.exited() {
+				return
+			}
+		case *eventAppQuit, nil:
+			t.Fatal("command did not exit")
+		}
+	}
+}
+
+func TestCommand_exitStatus(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name          string
+		args          []string
+		stopOnError   bool
+		wantStatus    string
+		wantWatchMode bool
+	}{
+		{
+			name:          "success",
+			args:          []string{"sh", "-c", "sleep 0.2; echo ok"},
+			stopOnError:   true,
+			wantStatus:    "[exit 0 ",
+			wantWatchMode: true,
+		},
+		{
+			name:          "error",
+			args:          []string{"sh", "-c", "sleep 0.2; exit 3"},
+			stopOnError:   false,
+			wantStatus:    "[exit 3 ",
+			wantWatchMode: true,
+		},
+		{
+			name:          "stop on error",
+			args:          []string{"sh", "-c", "sleep 0.2; exit 3"},
+			stopOnError:   true,
+			wantStatus:    "[exit 3 ",
+			wantWatchMode: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand(tt.args...)
+			cmd.StopOnError = tt.stopOnError
+			root, err := cmd.Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cmd.Wait()
+			cmd.docout.watchMode()
+			if !strings.Contains(cmd.docout.Caption, "[running since ") {
+				t.Errorf("Caption = %q, want running", cmd.docout.Caption)
+			}
+			// The caption is set in the event loop after the exit.
+			waitCommandExit(t, root)
+			if !strings.Contains(cmd.docerr.Caption, tt.wantStatus) {
+				t.Errorf("Caption = %q, want %q", cmd.docerr.Caption, tt.wantStatus)
+			}
+			if cmd.docout.WatchMode != tt.wantWatchMode {
+				t.Errorf("WatchMode = %v, want %v", cmd.docout.WatchMode, tt.wantWatchMode)
+			}
+		})
+	}
+}
+
+func TestCommand_ExecPty(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pty is supported only on linux")
+	}
+	if _, err := os.Stat("/dev/ptmx"); err != nil {
+		t.Skip("no pty device")
+	}
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	cmd := NewCommand("sh", "-c", "test -t 1 && echo tty; stty size; echo err >&2")
+	cmd.Pty = true
+	// Combined is ignored under a pty.
+	cmd.Combined = true
+	root, err := cmd.Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	if len(root.DocList) != 1 {
+		t.Fatalf("len(DocList) = %d, want 1", len(root.DocList))
+	}
+	if !strings.Contains(root.message, "--combined is ignored") {
+		t.Errorf("message = %q, want the notice that --combined is ignored", root.message)
+	}
+	cmd.mu.Lock()
+	run := cmd.run
+	cmd.mu.Unlock()
+	select {
+	case <-run.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not exit")
+	}
+	m := root.DocList[0]
+	for i := 0; i < 50 && !m.BufEOF(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	want := []string{"tty", fmt.Sprintf("%d %d", cmd.height, cmd.width), "err"}
+	for lN, w := range want {
+		if got := m.LineString(lN); got != w {
+			t.Errorf("line %d = %q, want %q", lN, got, w)
+		}
+	}
+}
+
+func TestCommand_ExecCombined(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	cmd := NewCommand("sh", "-c", "echo out1; sleep 0.1; echo err1 >&2; sleep 0.1; echo out2; sleep 0.1; printf err2 >&2")
+	cmd.Combined = true
+	root, err := cmd.Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	if len(root.DocList) != 3 {
+		t.Fatalf("len(DocList) = %d, want 3", len(root.DocList))
+	}
+	cmd.mu.Lock()
+	run := cmd.run
+	cmd.mu.Unlock()
+	select {
+	case <-run.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not exit")
+	}
+	m := root.DocList[0]
+	want := []string{"  out1", "\x1b[31mE\x1b[0m err1", "  out2", "\x1b[31mE\x1b[0m err2"}
+	for i := 0; i < 50 && m.BufEndNum() < len(want); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if m.BufEndNum() != len(want) {
+		t.Fatalf("BufEndNum() = %d, want %d", m.BufEndNum(), len(want))
+	}
+	for lN, w := range want {
+		if got := m.LineString(lN); got != w {
+			t.Errorf("line %d = %q, want %q", lN, got, w)
+		}
+	}
+	// The per-stream documents are still available.
+	if got := root.DocList[2].LineString(0); got != "err1" {
+		t.Errorf("stderr line 0 = %q, want %q", got, "err1")
+	}
+}
+
+func Test_parseSignal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input   string
+		want    syscall.Signal
+		wantErr bool
+	}{
+		{input: "INT", want: syscall.SIGINT},
+		{input: "sigterm", want: syscall.SIGTERM},
+		{input: " hup ", want: syscall.SIGHUP},
+		{input: "9", want: syscall.SIGKILL},
+		{input: "USR3", wantErr: true},
+		{input: "999", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSignal(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSignal(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSignal(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCommand_Signal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals other than kill are not supported on windows")
+	}
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	cmd := NewCommand("sleep", "10")
+	root, err := cmd.Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	if err := cmd.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	waitCommandExit(t, root)
+	if !strings.Contains(cmd.docout.Caption, "[signal: terminated ") {
+		t.Errorf("Caption = %q, want signal: terminated", cmd.docout.Caption)
+	}
+	if err := cmd.Signal(syscall.SIGTERM); !errors.Is(err, ErrCommandExited) {
+		t.Errorf("Signal() error = %v, want %v", err, ErrCommandExited)
+	}
+}
+
+func TestCommand_WriteInputPty(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pty is supported only on linux")
+	}
+	if _, err := os.Stat("/dev/ptmx"); err != nil {
+		t.Skip("no pty device")
+	}
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	cmd := NewCommand("sh", "-c", "stty -echo; read x; echo got $x")
+	cmd.Pty = true
+	root, err := cmd.Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	// Wait for stty to turn off the echo.
+	time.Sleep(200 * time.Millisecond)
+	if err := cmd.WriteInput("hello"); err != nil {
+		t.Fatal(err)
+	}
+	cmd.mu.Lock()
+	run := cmd.run
+	cmd.mu.Unlock()
+	select {
+	case <-run.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not exit")
+	}
+	m := root.DocList[0]
+	for i := 0; i < 50 && !m.BufEOF(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := m.LineString(m.BufEndNum() - 1); got != "got hello" {
+		t.Errorf("last line = %q, want %q", got, "got hello")
+	}
+}
+
+func TestCommand_WriteInputNoStdin(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	// The stdin is not connected unless Stdin is set.
+	cmd := NewCommand("sleep", "10")
+	if _, err := cmd.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	if err := cmd.WriteInput("hello"); !errors.Is(err, ErrNoStdin) {
+		t.Errorf("WriteInput() error = %v, want %v", err, ErrNoStdin)
+	}
+	if err := cmd.CloseInput(); !errors.Is(err, ErrNoStdin) {
+		t.Errorf("CloseInput() error = %v, want %v", err, ErrNoStdin)
+	}
+}
+
+func TestCommand_sendInput(t *testing.T) {
+	t.Parallel()
+	cmd := &Command{}
+	run := &commandRun{
+		input: make(chan []byte, 2),
+		done:  make(chan struct{}),
+	}
+	cmd.run = run
+	// The empty line is written as a line.
+	if err := cmd.WriteInput(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.WriteInput("a"); err != nil {
+		t.Fatal(err)
+	}
+	// The input is not blocked while the command does not read it.
+	if err := cmd.WriteInput("b"); !errors.Is(err, ErrStdinBusy) {
+		t.Errorf("WriteInput() error = %v, want %v", err, ErrStdinBusy)
+	}
+
+	w := &bytes.Buffer{}
+	finished := make(chan struct{})
+	go func() {
+		run.writeInput(w, nil)
+		close(finished)
+	}()
+	if err := cmd.CloseInput(); err != nil {
+		t.Fatal(err)
+	}
+	<-finished
+	if got := w.String(); got != "\na\n" {
+		t.Errorf("written = %q, want %q", got, "\na\n")
+	}
+	if err := cmd.WriteInput("c"); !errors.Is(err, ErrNoStdin) {
+		t.Errorf("WriteInput() after close error = %v, want %v", err, ErrNoStdin)
+	}
+}
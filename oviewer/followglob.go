@@ -0,0 +1,193 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// OpenGlob opens the files that match the patterns and creates root.
+// Files created later that match FollowGlob are opened while running.
+// If no file matches yet, an empty document waiting for the files is displayed.
+func OpenGlob(patterns ...string) (*Root, error) {
+	fileNames := globFiles(patterns)
+	if len(fileNames) > 0 {
+		return Open(fileNames...)
+	}
+
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	m.FileName = strings.Join(patterns, " ")
+	m.Caption = fmt.Sprintf("(waiting for %s)", m.FileName)
+	m.preventReload = true
+	if err := m.ControlReader(bytes.NewReader(nil), nil); err != nil {
+		return nil, err
+	}
+	root, err := NewOviewer(m)
+	if err != nil {
+		return nil, err
+	}
+	root.globWaiting = m
+	return root, nil
+}
+
+// globFiles returns the files that match the patterns.
+func globFiles(patterns []string) []string {
+	var fileNames []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Printf("glob %s: %s", pattern, err)
+			continue
+		}
+		fileNames = append(fileNames, matches...)
+	}
+	return fileNames
+}
+
+// watchGlob watches the directories of FollowGlob.
+func (root *Root) watchGlob(watcher *fsnotify.Watcher) {
+	for _, pattern := range root.Config.FollowGlob {
+		pattern, err := filepath.Abs(pattern)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		root.followGlobs = append(root.followGlobs, pattern)
+
+		dirs, err := filepath.Glob(filepath.Dir(pattern))
+		if err != nil {
+			log.Printf("glob %s: %s", pattern, err)
+			continue
+		}
+		for _, dir := range dirs {
+			if err := watcher.Add(dir); err != nil {
+				root.debugMessage(fmt.Sprintf("watcher %s:%s", dir, err))
+			}
+		}
+	}
+}
+
+// matchGlob returns true if the file name matches FollowGlob.
+func (root *Root) matchGlob(fileName string) bool {
+	for _, pattern := range root.followGlobs {
+		if ok, _ := filepath.Match(pattern, fileName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// globEvent opens the file created that matches FollowGlob,
+// and closes the document of the removed file if CloseRemoved is set.
+func (root *Root) globEvent(event fsnotify.Event) {
+	if len(root.followGlobs) == 0 {
+		return
+	}
+	switch {
+	case event.Op&fsnotify.Create != 0:
+		if !root.matchGlob(event.Name) || root.isOpened(event.Name) {
+			return
+		}
+		m, err := OpenDocument(event.Name)
+		if err != nil {
+			log.Printf("follow glob: %s", err)
+			return
+		}
+		m.filepath = event.Name
+		root.sendAddDocument(m)
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		if !root.CloseRemoved {
+			return
+		}
+		root.mu.RLock()
+		var removed *Document
+		for _, m := range root.DocList {
+			if m.filepath == event.Name {
+				removed = m
+			}
+		}
+		root.mu.RUnlock()
+		if removed != nil {
+			root.sendCloseDocumentOf(removed)
+		}
+	}
+}
+
+// isOpened returns true if the file has been opened.
+// The file is recorded as opened, because the document is added asynchronously.
+func (root *Root) isOpened(fileName string) bool {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	for _, m := range root.DocList {
+		if m.filepath == fileName {
+			return true
+		}
+	}
+	if root.globOpened == nil {
+		root.globOpened = make(map[string]bool)
+	}
+	if root.globOpened[fileName] {
+		return true
+	}
+	root.globOpened[fileName] = true
+	return false
+}
+
+// closeGlobWaiting closes the document waiting for FollowGlob
+// when the first file that matches is added.
+func (root *Root) closeGlobWaiting(m *Document) {
+	waiting := root.globWaiting
+	if waiting == nil || waiting == m || !root.matchGlob(m.filepath) {
+		return
+	}
+	root.globWaiting = nil
+	root.removeDocument(waiting)
+}
+
+// closeDocumentOf closes the specified document.
+func (root *Root) closeDocumentOf(m *Document) {
+	if root.DocumentLen() == 1 {
+		root.setMessageLogf("%s removed", m.FileName)
+		return
+	}
+	if root.removeDocument(m) {
+		root.setMessageLogf("close %s (removed)", m.FileName)
+	}
+}
+
+// removeDocument removes the specified document from the document list.
+// The last document is not removed.
+func (root *Root) removeDocument(m *Document) bool {
+	root.mu.Lock()
+	num := -1
+	for n, doc := range root.DocList {
+		if doc == m {
+			num = n
+		}
+	}
+	if num < 0 || len(root.DocList) == 1 {
+		root.mu.Unlock()
+		return false
+	}
+	m.requestClose()
+	m.store.release()
+	delete(root.globOpened, m.filepath)
+	root.DocList = append(root.DocList[:num], root.DocList[num+1:]...)
+	if root.CurrentDoc > num || root.CurrentDoc >= len(root.DocList) {
+		root.CurrentDoc--
+	}
+	doc := root.DocList[root.CurrentDoc]
+	root.mu.Unlock()
+
+	if root.Doc == m {
+		root.setDocument(doc)
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func globTestDir(t *testing.T, fileNames ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, fileName := range fileNames {
+		if err := os.WriteFile(filepath.Join(dir, fileName), []byte("test\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestOpenGlob(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name        string
+		fileNames   []string
+		pattern     string
+		wantDocs    int
+		wantCaption bool
+	}{
+		{
+			name:      "match",
+			fileNames: []string{"a.log", "b.log", "c.txt"},
+			pattern:   "*.log",
+			wantDocs:  2,
+		},
+		{
+			name:        "no match",
+			fileNames:   []string{"c.txt"},
+			pattern:     "*.log",
+			wantDocs:    1,
+			wantCaption: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := globTestDir(t, tt.fileNames...)
+			root, err := OpenGlob(filepath.Join(dir, tt.pattern))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := root.DocumentLen(); got != tt.wantDocs {
+				t.Errorf("DocumentLen() = %d, want %d", got, tt.wantDocs)
+			}
+			if got := root.Doc.Caption != ""; got != tt.wantCaption {
+				t.Errorf("Caption = %q, want caption %v", root.Doc.Caption, tt.wantCaption)
+			}
+		})
+	}
+}
+
+func TestRoot_matchGlob(t *testing.T) {
+	t.Parallel()
+	root := &Root{
+		followGlobs: []string{"/var/log/app/*.log", "/tmp/*/test.txt"},
+	}
+	tests := []struct {
+		fileName string
+		want     bool
+	}{
+		{fileName: "/var/log/app/a.log", want: true},
+		{fileName: "/var/log/app/a.log.1", want: false},
+		{fileName: "/var/log/other/a.log", want: false},
+		{fileName: "/tmp/dir/test.txt", want: true},
+	}
+	for _, tt := range tests {
+		if got := root.matchGlob(tt.fileName); got != tt.want {
+			t.Errorf("matchGlob(%s) = %v, want %v", tt.fileName, got, tt.want)
+		}
+	}
+}
+
+func TestRoot_closeDocumentOf(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name        string
+		current     int
+		close       int
+		wantDocs    int
+		wantCurrent string
+	}{
+		{name: "before current", current: 2, close: 0, wantDocs: 2, wantCurrent: "c.log"},
+		{name: "current", current: 1, close: 1, wantDocs: 2, wantCurrent: "c.log"},
+		{name: "last current", current: 2, close: 2, wantDocs: 2, wantCurrent: "b.log"},
+		{name: "after current", current: 0, close: 2, wantDocs: 2, wantCurrent: "a.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := globTestDir(t, "a.log", "b.log", "c.log")
+			root, err := OpenGlob(filepath.Join(dir, "*.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			root.setDocumentNum(tt.current)
+			root.closeDocumentOf(root.DocList[tt.close])
+			if got := root.DocumentLen(); got != tt.wantDocs {
+				t.Errorf("DocumentLen() = %d, want %d", got, tt.wantDocs)
+			}
+			if got := filepath.Base(root.Doc.FileName); got != tt.wantCurrent {
+				t.Errorf("current = %s, want %s", got, tt.wantCurrent)
+			}
+			if got := filepath.Base(root.DocList[root.CurrentDoc].FileName); got != tt.wantCurrent {
+				t.Errorf("DocList[CurrentDoc] = %s, want %s", got, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestRoot_closeGlobWaiting(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	dir := globTestDir(t, "c.txt")
+	root, err := OpenGlob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.followGlobs = []string{filepath.Join(dir, "*.log")}
+
+	other, err := OpenDocument(filepath.Join(dir, "c.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.filepath = filepath.Join(dir, "c.txt")
+	root.addDocument(other)
+	if got := root.DocumentLen(); got != 2 {
+		t.Fatalf("DocumentLen() = %d, want 2 (not matched)", got)
+	}
+
+	fileName := filepath.Join(dir, "a.log")
+	if err := os.WriteFile(fileName, []byte("test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.filepath = fileName
+	root.addDocument(m)
+	if got := root.DocumentLen(); got != 2 {
+		t.Errorf("DocumentLen() = %d, want 2", got)
+	}
+	for _, doc := range root.DocList {
+		if doc.Caption != "" {
+			t.Errorf("the waiting document %s is not closed", doc.FileName)
+		}
+	}
+	if root.Doc != m || root.DocList[root.CurrentDoc] != m {
+		t.Errorf("current = %s, want %s", root.Doc.FileName, m.FileName)
+	}
+	// Only the documents of FollowGlob are followed.
+	if !m.FollowMode || other.FollowMode {
+		t.Errorf("FollowMode = %v, %v, want true, false", m.FollowMode, other.FollowMode)
+	}
+	if root.General.FollowAll {
+		t.Errorf("FollowAll = true, want false")
+	}
+}
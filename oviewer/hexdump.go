@@ -0,0 +1,406 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// hexBytesPerRow is the number of bytes displayed in a row of the hex dump.
+	hexBytesPerRow = 16
+	// hexReadRows is the maximum number of rows read at once.
+	hexReadRows = 256
+	// hexSearchSize is the size of the block read when searching for bytes.
+	hexSearchSize = 64 * 1024
+)
+
+// hexDump is the source of the hex dump.
+// The hex dump is displayed in the format of `hexdump -C`.
+//
+//	00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 0a 00 01 02  |Hello, world....|
+type hexDump struct {
+	src io.ReaderAt
+	// size is the size of the source.
+	size int64
+	// width is the number of digits of the offset.
+	width int
+}
+
+// newHexDump returns a hexDump of the source.
+func newHexDump(src io.ReaderAt, size int64) *hexDump {
+	width := max(8, len(strconv.FormatInt(size, 16)))
+	return &hexDump{
+		src:   src,
+		size:  size,
+		width: width,
+	}
+}
+
+// rowLen returns the length of a row including the newline.
+// All rows have the same length, so the position of the row can be calculated.
+func (h *hexDump) rowLen() int64 {
+	return int64(h.width + 2 + hexBytesPerRow*3 + 1 + 1 + hexBytesPerRow + 2 + 1)
+}
+
+// rows returns the number of rows.
+func (h *hexDump) rows() int64 {
+	return (h.size + hexBytesPerRow - 1) / hexBytesPerRow
+}
+
+// appendRow appends a row of data at offset to buf.
+func (h *hexDump) appendRow(buf []byte, offset int64, data []byte) []byte {
+	start := len(buf)
+	off := strconv.FormatInt(offset, 16)
+	for i := len(off); i < h.width; i++ {
+		buf = append(buf, '0')
+	}
+	buf = append(buf, off...)
+	buf = append(buf, ' ', ' ')
+	for i := 0; i < hexBytesPerRow; i++ {
+		if i == hexBytesPerRow/2 {
+			buf = append(buf, ' ')
+		}
+		if i < len(data) {
+			buf = append(buf, hex.EncodeToString(data[i:i+1])...)
+			buf = append(buf, ' ')
+		} else {
+			buf = append(buf, ' ', ' ', ' ')
+		}
+	}
+	buf = append(buf, ' ', '|')
+	for _, b := range data {
+		if b < 0x20 || b >= 0x7f {
+			b = '.'
+		}
+		buf = append(buf, b)
+	}
+	buf = append(buf, '|')
+	// Pad the last row so that all rows have the same length.
+	for int64(len(buf)-start) < h.rowLen()-1 {
+		buf = append(buf, ' ')
+	}
+	return append(buf, '\n')
+}
+
+// hexDumpReader is an io.ReadSeeker that reads the hex dump text.
+type hexDumpReader struct {
+	h   *hexDump
+	pos int64
+	buf []byte
+}
+
+// newHexDumpReader returns an io.ReadSeeker of the hex dump text.
+func newHexDumpReader(h *hexDump) *hexDumpReader {
+	return &hexDumpReader{h: h}
+}
+
+// Read reads the hex dump text.
+func (r *hexDumpReader) Read(p []byte) (int, error) {
+	rowLen := r.h.rowLen()
+	total := r.h.rows() * rowLen
+	if r.pos >= total {
+		return 0, io.EOF
+	}
+	row := r.pos / rowLen
+	col := r.pos % rowLen
+	num := min(min((col+int64(len(p))+rowLen-1)/rowLen, r.h.rows()-row), hexReadRows)
+
+	data := make([]byte, num*hexBytesPerRow)
+	n, err := r.h.src.ReadAt(data, row*hexBytesPerRow)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	data = data[:n]
+
+	r.buf = r.buf[:0]
+	for i := int64(0); i < num; i++ {
+		start := min(i*hexBytesPerRow, int64(len(data)))
+		end := min(start+hexBytesPerRow, int64(len(data)))
+		r.buf = r.h.appendRow(r.buf, (row+i)*hexBytesPerRow, data[start:end])
+	}
+	c := copy(p, r.buf[col:])
+	r.pos += int64(c)
+	return c, nil
+}
+
+// Seek sets the position of the hex dump text.
+func (r *hexDumpReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.h.rows() * r.h.rowLen()
+	}
+	if offset < 0 {
+		return r.pos, ErrOutOfRange
+	}
+	r.pos = offset
+	return r.pos, nil
+}
+
+// storeReaderAt is an io.ReaderAt of the contents of the store.
+// It is used for non-seekable files whose contents are in memory.
+type storeReaderAt struct {
+	s *store
+}
+
+// ReadAt reads the contents at offset from the chunks in memory.
+func (r storeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s := r.s
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chunkNum := sort.Search(len(s.chunks), func(i int) bool {
+		return s.chunks[i].start > off
+	}) - 1
+	n := 0
+	for chunkNum = max(chunkNum, 0); chunkNum < len(s.chunks); chunkNum++ {
+		chunk := s.chunks[chunkNum]
+		pos := chunk.start
+		for _, line := range chunk.lines {
+			end := pos + int64(len(line))
+			if cur := off + int64(n); cur >= pos && cur < end {
+				n += copy(p[n:], line[cur-pos:])
+				if n == len(p) {
+					return n, nil
+				}
+			}
+			pos = end
+		}
+	}
+	return n, io.EOF
+}
+
+// seekerAt is an io.ReaderAt using io.ReadSeeker.
+type seekerAt struct {
+	mu sync.Mutex
+	rs io.ReadSeeker
+}
+
+// ReadAt seeks and reads.
+func (s *seekerAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(s.rs, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// hexSource returns the source of the hex dump and its size.
+// Regular files are read from the file, compressed files are decompressed again,
+// and other files are read from the memory.
+func (m *Document) hexSource() (io.ReaderAt, int64, error) {
+	if m.hex != nil {
+		return m.hex.src, m.hex.size, nil
+	}
+	switch {
+	case m.seekable && m.file != nil && m.CFormat == UNCOMPRESSED:
+		fi, err := m.file.Stat()
+		if err != nil {
+			return nil, 0, err
+		}
+		return m.file, fi.Size(), nil
+	case !m.BufEOF():
+		// The size is unknown until the end is read.
+		return nil, 0, ErrNotEOF
+	case m.seekable && m.file != nil:
+		m.store.mu.RLock()
+		size := m.store.size
+		if ds, ok := m.seeker.(*decodeSeeker); ok {
+			// The size before decoding.
+			size = ds.size
+		}
+		m.store.mu.RUnlock()
+		return &seekerAt{rs: newCompressedSeeker(m.file, m.CFormat)}, size, nil
+	case m.seekable:
+		return nil, 0, ErrNoHexSource
+	}
+	if m.BufStartNum() > 0 {
+		return nil, 0, ErrNotLoaded
+	}
+	m.store.mu.RLock()
+	size := m.store.size
+	m.store.mu.RUnlock()
+	return storeReaderAt{s: m.store}, size, nil
+}
+
+// hexDocument returns a new document that displays the hex dump of the document.
+func (m *Document) hexDocument() (*Document, error) {
+	src, size, err := m.hexSource()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.hex = newHexDump(src, size)
+	doc.FileName = m.FileName
+	doc.Caption = "(hex)" + m.FileName
+	doc.preventReload = true
+	if err := doc.ControlSeeker(newHexDumpReader(doc.hex)); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// hexView adds a document that displays the current document in hex.
+func (root *Root) hexView() {
+	m := root.Doc
+	if m.hex != nil {
+		root.setMessage("already hex view")
+		return
+	}
+	doc, err := m.hexDocument()
+	if err != nil {
+		root.setMessagef("hex view: %s", err)
+		return
+	}
+	root.addDocument(doc)
+	doc.general.ColumnMode = false
+	doc.general.WrapMode = false
+}
+
+// notifyBinary displays a message if the document looks like binary.
+func (root *Root) notifyBinary() {
+	m := root.Doc
+	if atomic.LoadInt32(&m.binary) == 0 || atomic.SwapInt32(&m.binaryNotified, 1) == 1 {
+		return
+	}
+	root.setMessagef("binary file: press %s to display in hex", strings.Join(root.hexViewKeys, ","))
+}
+
+// isOffset returns true if the input is an offset (e.g. 0x1f0).
+func isOffset(input string) bool {
+	return strings.HasPrefix(input, "0x") || strings.HasPrefix(input, "0X")
+}
+
+// goOffset moves to the row of the offset in the hex view.
+func (root *Root) goOffset(input string) {
+	m := root.Doc
+	offset, err := strconv.ParseInt(input[2:], 16, 64)
+	if err != nil || offset < 0 {
+		root.setMessage(ErrInvalidNumber.Error())
+		return
+	}
+	if offset >= m.hex.size {
+		root.setMessagef("offset %s: %s", input, ErrOutOfRange)
+		return
+	}
+	m.moveLine(int(offset / hexBytesPerRow))
+	root.setMessagef("Moved to offset 0x%x", offset)
+}
+
+// hexPattern returns the bytes of the search pattern in the hex view.
+// The pattern is hex digits prefixed with 0x or separated by spaces
+// (e.g. "0xdeadbeef" or "de ad be ef").
+// Other patterns are searched as text.
+func hexPattern(input string) ([]byte, bool) {
+	if !isOffset(input) && !strings.Contains(input, " ") {
+		return nil, false
+	}
+	str := strings.ReplaceAll(input, " ", "")
+	if isOffset(str) {
+		str = str[2:]
+	}
+	if str == "" || len(str)%2 != 0 {
+		return nil, false
+	}
+	pattern, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, false
+	}
+	return pattern, true
+}
+
+// searchBytes searches the pattern from the offset and returns the offset of the match.
+// Forward search returns the first match at or after the offset,
+// and backward search returns the last match at or before the offset.
+func (h *hexDump) searchBytes(ctx context.Context, pattern []byte, offset int64, forward bool) (int64, error) {
+	overlap := int64(len(pattern) - 1)
+	buf := make([]byte, hexSearchSize+overlap)
+	for offset >= 0 && offset < h.size {
+		select {
+		case <-ctx.Done():
+			return 0, ErrCancel
+		default:
+		}
+
+		start := offset
+		if !forward {
+			start = max(offset-hexSearchSize+1, 0)
+		}
+		end := min(min(start+hexSearchSize, offset+1)+overlap, h.size)
+		if forward {
+			end = min(start+hexSearchSize+overlap, h.size)
+		}
+		n, err := h.src.ReadAt(buf[:end-start], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if forward {
+			if i := bytes.Index(buf[:n], pattern); i >= 0 {
+				return start + int64(i), nil
+			}
+			offset += hexSearchSize
+			continue
+		}
+		// Only the matches starting at or before offset are candidates.
+		limit := min(int64(n), offset-start+1+overlap)
+		if i := bytes.LastIndex(buf[:limit], pattern); i >= 0 {
+			return start + int64(i), nil
+		}
+		offset = start - 1
+	}
+	return 0, ErrNotFound
+}
+
+// hexSearchMove searches the bytes in the hex view and moves to the row of the match.
+func (root *Root) hexSearchMove(ctx context.Context, forward bool, lN int, pattern []byte) {
+	h := root.Doc.hex
+	root.setMessagef("search:0x%x (%v)Cancel", pattern, strings.Join(root.cancelKeys, ","))
+	eg, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eg.Go(func() error {
+		return root.cancelWait(cancel)
+	})
+
+	eg.Go(func() error {
+		offset := int64(max(lN, 0)) * hexBytesPerRow
+		if !forward {
+			offset += hexBytesPerRow - 1
+		}
+		found, err := h.searchBytes(ctx, pattern, offset, forward)
+		root.sendSearchQuit()
+		if err != nil {
+			return fmt.Errorf("search:%w:0x%x", err, pattern)
+		}
+		root.sendSearchMove(int(found / hexBytesPerRow))
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		root.setMessageLog(err.Error())
+		return
+	}
+	root.setMessage("")
+}
@@ -0,0 +1,276 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_hexDump_appendRow(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		size   int64
+		offset int64
+		data   []byte
+		want   string
+	}{
+		{
+			name:   "full row",
+			size:   32,
+			offset: 16,
+			data:   []byte("Hello, world\n\x00\x01\x02"),
+			want:   "00000010  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 0a 00 01 02  |Hello, world....|\n",
+		},
+		{
+			name:   "short row",
+			size:   3,
+			offset: 0,
+			data:   []byte("ov\x7f"),
+			want:   "00000000  6f 76 7f                                          |ov.|             \n",
+		},
+		{
+			name:   "wide offset",
+			size:   0x123456789,
+			offset: 0x100,
+			data:   []byte("0123456789abcdef"),
+			want:   "000000100  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := newHexDump(nil, tt.size)
+			got := string(h.appendRow(nil, tt.offset, tt.data))
+			if got != tt.want {
+				t.Errorf("appendRow() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if int64(len(got)) != h.rowLen() {
+				t.Errorf("len = %d, want %d", len(got), h.rowLen())
+			}
+		})
+	}
+}
+
+func Test_hexDumpReader(t *testing.T) {
+	t.Parallel()
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := newHexDump(bytes.NewReader(data), int64(len(data)))
+	r := newHexDumpReader(h)
+	all, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(all), "\n"), "\n")
+	if int64(len(lines)) != h.rows() {
+		t.Fatalf("rows = %d, want %d", len(lines), h.rows())
+	}
+
+	// Seek to the middle of a row.
+	pos := 10*h.rowLen() + 5
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, h.rowLen())
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := all[pos : pos+h.rowLen()]; !bytes.Equal(buf, want) {
+		t.Errorf("Read() = %q, want %q", buf, want)
+	}
+	if !strings.HasPrefix(lines[62], "000003e0  e0 e1 e2 e3 e4 e5 e6 e7") {
+		t.Errorf("last row = %q", lines[62])
+	}
+}
+
+func Test_storeReaderAt(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(strings.Repeat("0123456789\n", 25000))
+	if err := m.ControlReader(bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	src, size, err := m.hexSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(data)) {
+		t.Fatalf("size = %d, want %d", size, len(data))
+	}
+	tests := []struct {
+		name string
+		off  int64
+		len  int
+	}{
+		{name: "first", off: 0, len: 16},
+		{name: "middle of line", off: 5, len: 20},
+		{name: "across chunks", off: 10000*11 - 8, len: 16},
+		{name: "last", off: int64(len(data)) - 4, len: 4},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := make([]byte, tt.len)
+			n, err := src.ReadAt(buf, tt.off)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := data[tt.off : tt.off+int64(tt.len)]; !bytes.Equal(buf[:n], want) {
+				t.Errorf("ReadAt() = %q, want %q", buf[:n], want)
+			}
+		})
+	}
+	if _, err := src.ReadAt(make([]byte, 8), int64(len(data))-4); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadAt() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func Test_hexPattern(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   []byte
+		wantOk bool
+	}{
+		{name: "prefix", input: "0xdeadbeef", want: []byte{0xde, 0xad, 0xbe, 0xef}, wantOk: true},
+		{name: "spaces", input: "7f 45 4c 46", want: []byte{0x7f, 'E', 'L', 'F'}, wantOk: true},
+		{name: "text", input: "cafe", wantOk: false},
+		{name: "odd", input: "0xabc", wantOk: false},
+		{name: "not hex", input: "error log", wantOk: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := hexPattern(tt.input)
+			if ok != tt.wantOk {
+				t.Fatalf("hexPattern() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("hexPattern() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hexDump_searchBytes(t *testing.T) {
+	t.Parallel()
+	data := make([]byte, hexSearchSize*3)
+	pattern := []byte{0xde, 0xad, 0xbe, 0xef}
+	// Across the boundary of the search block.
+	copy(data[hexSearchSize-2:], pattern)
+	copy(data[100:], pattern)
+	h := newHexDump(bytes.NewReader(data), int64(len(data)))
+	tests := []struct {
+		name    string
+		offset  int64
+		forward bool
+		want    int64
+		wantErr error
+	}{
+		{name: "forward", offset: 0, forward: true, want: 100},
+		{name: "forward boundary", offset: 101, forward: true, want: hexSearchSize - 2},
+		{name: "forward not found", offset: hexSearchSize, forward: true, wantErr: ErrNotFound},
+		{name: "backward", offset: int64(len(data)) - 1, forward: false, want: hexSearchSize - 2},
+		{name: "backward same", offset: 100, forward: false, want: 100},
+		{name: "backward not found", offset: 99, forward: false, wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := h.searchBytes(context.Background(), pattern, tt.offset, tt.forward)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("searchBytes() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("searchBytes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_hexDocument(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "test.bin")
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if err := os.WriteFile(fileName, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	if atomic.LoadInt32(&m.binary) != 1 {
+		t.Errorf("binary = %d, want 1", m.binary)
+	}
+	doc, err := m.hexDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !doc.BufEOF() {
+	}
+	if got, want := doc.BufEndNum(), len(data)/hexBytesPerRow; got != want {
+		t.Fatalf("BufEndNum() = %d, want %d", got, want)
+	}
+	lN := 0x1f40 / hexBytesPerRow
+	want := string(doc.hex.appendRow(nil, 0x1f40, data[0x1f40:0x1f50]))
+	if got := indexTestLine(t, doc, lN); got != strings.TrimSuffix(want, "\n") {
+		t.Errorf("line %d = %q, want %q", lN, got, want)
+	}
+
+	text := openIndexTestDocument(t, filepath.Join("..", "testdata", "normal.txt"))
+	if atomic.LoadInt32(&text.binary) != 0 {
+		t.Errorf("binary = %d, want 0", text.binary)
+	}
+}
+
+func Test_hexDump_searchBytesBackward(t *testing.T) {
+	t.Parallel()
+	data := make([]byte, hexSearchSize*3)
+	pattern := []byte{0xde, 0xad, 0xbe, 0xef}
+	copy(data[10:], pattern)
+	// Across the boundary of the second search block from the end.
+	copy(data[hexSearchSize*2-2:], pattern)
+	h := newHexDump(bytes.NewReader(data), int64(len(data)))
+	tests := []struct {
+		name   string
+		offset int64
+		want   int64
+	}{
+		{name: "boundary", offset: int64(len(data)) - 1, want: hexSearchSize*2 - 2},
+		{name: "start of match", offset: hexSearchSize*2 - 2, want: hexSearchSize*2 - 2},
+		{name: "before match", offset: hexSearchSize*2 - 3, want: 10},
+		{name: "inside match", offset: hexSearchSize*2 - 1, want: hexSearchSize*2 - 2},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := h.searchBytes(context.Background(), pattern, tt.offset, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("searchBytes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package oviewer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// indexVersion is the version of the index file format.
+const indexVersion = 1
+
+// lineIndex is the line index of a file saved in the cache.
+// It holds the start position of each chunk,
+// so the file can be displayed without counting the lines again.
+type lineIndex struct {
+	// ModTime is the modification time of the file.
+	ModTime time.Time
+	// FileName is the absolute path of the file.
+	FileName string
+	// Starts is the start position of each chunk.
+	Starts []int64
+	// Size is the size of the file.
+	Size int64
+	// Inode is the inode number of the file (0 if not supported).
+	Inode uint64
+	// Version is the version of the index file format.
+	Version int
+	// ChunkSize is the number of lines in a chunk.
+	ChunkSize int
+	// EndNum is the number of lines.
+	EndNum int
+	// NoNewlineEOF is true if the file does not end with a newline.
+	NoNewlineEOF bool
+}
+
+// indexPath returns the path of the index file of the file.
+func indexPath(fileName string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(fileName))
+	return filepath.Join(cacheDir, "ov", "index", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// readIndex reads the index file of the file.
+func readIndex(fileName string) (*lineIndex, error) {
+	path, err := indexPath(fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := &lineIndex{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	if idx.Version != indexVersion || idx.FileName != fileName || idx.ChunkSize != ChunkSize {
+		return nil, ErrInvalidIndex
+	}
+	if len(idx.Starts) == 0 || idx.Starts[0] != 0 || len(idx.Starts) != (idx.EndNum+ChunkSize-1)/ChunkSize {
+		return nil, ErrInvalidIndex
+	}
+	return idx, nil
+}
+
+// writeIndex writes the index file.
+func writeIndex(idx *lineIndex) error {
+	path, err := indexPath(idx.FileName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it so that a broken file is not left.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "index")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// useIndex returns whether the index cache can be used for the document.
+func (m *Document) useIndex() bool {
+	if sep, _ := m.store.separator(); sep != '\n' {
+		return false
+	}
+	return m.indexCache && m.seekable && m.CFormat == UNCOMPRESSED && !m.decoded() && m.filepath != "" && len(m.store.pinned) == 0
+}
+
+// loadIndex returns the index if it matches the current file.
+// If the file has only been appended, the index is returned with appended set to true.
+func (m *Document) loadIndex() (idx *lineIndex, appended bool) {
+	if !m.useIndex() {
+		return nil, false
+	}
+	idx, err := readIndex(m.filepath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("index: %s", err)
+		}
+		return nil, false
+	}
+	fi, err := m.file.Stat()
+	if err != nil {
+		return nil, false
+	}
+	if idx.Inode != fileInode(fi) {
+		return nil, false
+	}
+	if fi.Size() == idx.Size && fi.ModTime().Equal(idx.ModTime) {
+		return idx, false
+	}
+	// Appended files can reuse the index
+	// if the indexed part ends with a newline.
+	if fi.Size() > idx.Size && !idx.NoNewlineEOF {
+		return idx, true
+	}
+	return nil, false
+}
+
+// validIndex checks that the sampled chunks of the index start at the beginning of a line.
+// The end of the index is also checked unless the file does not end with a newline.
+// The file is the same as the indexed one in size, modification time and inode,
+// so the other chunks are checked lazily when they are loaded (see verifyChunk).
+func (m *Document) validIndex(idx *lineIndex) bool {
+	check := make([]int64, 0, 4)
+	for _, chunkNum := range indexSampleChunks(len(idx.Starts)) {
+		check = append(check, idx.Starts[chunkNum])
+	}
+	if !idx.NoNewlineEOF {
+		check = append(check, idx.Size)
+	}
+	for _, start := range check {
+		if !m.lineStartAt(start) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexSampleChunks returns the numbers of the chunks checked when the index is loaded
+// (the second, the middle and the last chunk).
+func indexSampleChunks(n int) []int {
+	if n <= 1 {
+		return nil
+	}
+	return []int{1, n / 2, n - 1}
+}
+
+// lineStartAt returns true if the position of the file is the beginning of a line.
+func (m *Document) lineStartAt(start int64) bool {
+	if start <= 0 {
+		return true
+	}
+	b := make([]byte, 1)
+	if _, err := m.file.ReadAt(b, start-1); err != nil {
+		return false
+	}
+	return b[0] == '\n'
+}
+
+// verifyChunk checks the start of the chunk read from the index when it is loaded.
+// If it is not the beginning of a line, the index is removed and the file is reloaded.
+func (m *Document) verifyChunk(chunkNum int) error {
+	s := m.store
+	s.mu.RLock()
+	chunk := s.chunks[chunkNum]
+	unverified, start := chunk.unverified, chunk.start
+	s.mu.RUnlock()
+	if !unverified {
+		return nil
+	}
+	if !m.lineStartAt(start) {
+		if err := removeIndex(m.filepath); err != nil {
+			log.Printf("index: %s", err)
+		}
+		go m.requestReload()
+		return fmt.Errorf("%w: chunk %d", ErrInvalidIndex, chunkNum)
+	}
+	s.mu.Lock()
+	chunk.unverified = false
+	s.mu.Unlock()
+	return nil
+}
+
+// indexRead reads the first chunk and reserves the other chunks from the index.
+// If the file has been appended, continue reading from the end of the index.
+func (m *Document) indexRead(reader *bufio.Reader, idx *lineIndex, appended bool) (*bufio.Reader, error) {
+	chunk := m.store.chunks[0]
+	end := min(ChunkSize, idx.EndNum)
+	if err := m.store.readLines(chunk, reader, 0, end, false); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	sampled := make(map[int]bool)
+	for _, chunkNum := range indexSampleChunks(len(idx.Starts)) {
+		sampled[chunkNum] = true
+	}
+	m.store.mu.Lock()
+	for n, start := range idx.Starts[1:] {
+		chunk := NewChunk(start)
+		chunk.unverified = !sampled[n+1]
+		m.store.chunks = append(m.store.chunks, chunk)
+	}
+	m.store.size = idx.Size
+	m.store.offset = idx.Size
+	m.store.mu.Unlock()
+	atomic.StoreInt32(&m.store.endNum, int32(idx.EndNum))
+	if idx.NoNewlineEOF {
+		atomic.StoreInt32(&m.store.noNewlineEOF, 1)
+	}
+	atomic.StoreInt32(&m.store.changed, 1)
+	m.indexNum = idx.EndNum
+	log.Printf("index: %s %d lines", m.FileName, idx.EndNum)
+
+	if !appended {
+		atomic.StoreInt32(&m.store.eof, 1)
+		return reader, nil
+	}
+
+	// Count the appended lines of the last chunk.
+	if err := m.seekChunk(reader, idx.Size); err != nil {
+		return nil, err
+	}
+	if rest := idx.EndNum % ChunkSize; rest != 0 {
+		if err := m.reserveChunk(reader, rest, ChunkSize); err != nil {
+			if errors.Is(err, io.EOF) {
+				return m.afterEOF(reader), nil
+			}
+			return nil, err
+		}
+	}
+	m.requestContinue()
+	return reader, nil
+}
+
+// saveIndex saves the index of the document in the cache.
+// Files that fit in one chunk are not saved.
+func (m *Document) saveIndex() {
+	if !m.useIndex() || atomic.LoadInt32(&m.tmpFollow) == 1 {
+		return
+	}
+	endNum := m.storeEndNum()
+	if endNum <= ChunkSize || endNum == m.indexNum {
+		return
+	}
+	fi, err := m.file.Stat()
+	if err != nil {
+		return
+	}
+
+	m.store.mu.RLock()
+	// The empty chunk after the last line is not saved.
+	chunks := m.store.chunks[:min(len(m.store.chunks), (endNum+ChunkSize-1)/ChunkSize)]
+	starts := make([]int64, 0, len(chunks))
+	for _, chunk := range chunks {
+		starts = append(starts, chunk.start)
+	}
+	size := m.store.size
+	m.store.mu.RUnlock()
+	// The file has been changed while reading.
+	if size != fi.Size() {
+		return
+	}
+
+	idx := &lineIndex{
+		Version:      indexVersion,
+		FileName:     m.filepath,
+		Size:         size,
+		ModTime:      fi.ModTime(),
+		Inode:        fileInode(fi),
+		ChunkSize:    ChunkSize,
+		EndNum:       endNum,
+		NoNewlineEOF: atomic.LoadInt32(&m.store.noNewlineEOF) == 1,
+		Starts:       starts,
+	}
+	if err := writeIndex(idx); err != nil {
+		log.Printf("index: %s", err)
+		return
+	}
+	m.indexNum = endNum
+}
+
+// removeIndex removes the index file of the file.
+func removeIndex(fileName string) error {
+	path, err := indexPath(fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package oviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIndexTestFile(t *testing.T, fileName string, start int, end int, flag int) {
+	t.Helper()
+	f, err := os.OpenFile(fileName, flag, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openIndexTestDocument(t *testing.T, fileName string) *Document {
+	t.Helper()
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	return m
+}
+
+func indexTestLine(t *testing.T, m *Document, lN int) string {
+	t.Helper()
+	var str string
+	err := m.rangeLines(context.Background(), lN, lN+1, func(_ int, line []byte) error {
+		str = string(line)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return str
+}
+
+func TestDocument_indexCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	indexCache := IndexCache
+	IndexCache = true
+	defer func() {
+		IndexCache = indexCache
+	}()
+
+	fileName := filepath.Join(t.TempDir(), "index.txt")
+	writeIndexTestFile(t, fileName, 0, 25000, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+
+	m := openIndexTestDocument(t, fileName)
+	if got := m.BufEndNum(); got != 25000 {
+		t.Fatalf("BufEndNum() = %d, want 25000", got)
+	}
+	path, err := indexPath(m.filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("index file is not saved: %s", err)
+	}
+	idx, err := readIndex(m.filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Starts) != 3 || idx.EndNum != 25000 {
+		t.Fatalf("index = %d chunks %d lines, want 3 chunks 25000 lines", len(idx.Starts), idx.EndNum)
+	}
+
+	t.Run("reopen", func(t *testing.T) {
+		m := openIndexTestDocument(t, fileName)
+		if m.indexNum != 25000 {
+			t.Errorf("indexNum = %d, want 25000", m.indexNum)
+		}
+		if got := m.BufEndNum(); got != 25000 {
+			t.Errorf("BufEndNum() = %d, want 25000", got)
+		}
+		if got := indexTestLine(t, m, 24999); got != "line 24999" {
+			t.Errorf("line = %q, want %q", got, "line 24999")
+		}
+	})
+
+	t.Run("appended", func(t *testing.T) {
+		writeIndexTestFile(t, fileName, 25000, 32000, os.O_APPEND|os.O_WRONLY)
+		m := openIndexTestDocument(t, fileName)
+		if got := m.BufEndNum(); got != 32000 {
+			t.Fatalf("BufEndNum() = %d, want 32000", got)
+		}
+		if got := indexTestLine(t, m, 31999); got != "line 31999" {
+			t.Errorf("line = %q, want %q", got, "line 31999")
+		}
+		if got := indexTestLine(t, m, 25000); got != "line 25000" {
+			t.Errorf("line = %q, want %q", got, "line 25000")
+		}
+	})
+
+	t.Run("rewritten", func(t *testing.T) {
+		writeIndexTestFile(t, fileName, 100, 12100, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+		m := openIndexTestDocument(t, fileName)
+		if m.indexNum == 32000 {
+			t.Errorf("index of the rewritten file is used")
+		}
+		if got := m.BufEndNum(); got != 12000 {
+			t.Errorf("BufEndNum() = %d, want 12000", got)
+		}
+		if got := indexTestLine(t, m, 11999); got != "line 12099" {
+			t.Errorf("line = %q, want %q", got, "line 12099")
+		}
+	})
+}
+
+func TestDocument_indexCacheNoNewlineEOF(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	indexCache := IndexCache
+	IndexCache = true
+	defer func() {
+		IndexCache = indexCache
+	}()
+
+	fileName := filepath.Join(t.TempDir(), "index.txt")
+	writeIndexTestFile(t, fileName, 0, 14999, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("last line"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	openIndexTestDocument(t, fileName)
+	idx, err := readIndex(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !idx.NoNewlineEOF {
+		t.Fatal("index.NoNewlineEOF = false, want true")
+	}
+
+	m := openIndexTestDocument(t, fileName)
+	if m.indexNum != 15000 {
+		t.Errorf("indexNum = %d, want 15000", m.indexNum)
+	}
+	if got := indexTestLine(t, m, 14999); got != "last line" {
+		t.Errorf("line = %q, want %q", got, "last line")
+	}
+
+	// validIndex does not modify the starts of the index.
+	starts := make([]int64, len(idx.Starts), len(idx.Starts)+1)
+	copy(starts, idx.Starts)
+	idx.Starts = starts
+	if !m.validIndex(idx) {
+		t.Error("validIndex() = false, want true")
+	}
+	if got := starts[:cap(starts)][len(starts)]; got != 0 {
+		t.Errorf("validIndex() modified the starts: %d", got)
+	}
+}
+
+func TestDocument_verifyChunk(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	indexCache := IndexCache
+	IndexCache = true
+	defer func() {
+		IndexCache = indexCache
+	}()
+
+	// Lines of the same length, so that the file can be rewritten in the same size.
+	fileName := filepath.Join(t.TempDir(), "index.txt")
+	var b strings.Builder
+	for i := 0; i < ChunkSize*5; i++ {
+		fmt.Fprintf(&b, "%09d\n", i)
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	openIndexTestDocument(t, fileName)
+	fi, err := os.Stat(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the end of the line before the chunk 3 that is not sampled.
+	f, err := os.OpenFile(fileName, os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("x"), int64(ChunkSize*3*10-1)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.Chtimes(fileName, fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := OpenDocument(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	// The index of the lines of exactly five chunks is used.
+	if m.indexNum != ChunkSize*5 {
+		t.Fatalf("indexNum = %d, want %d", m.indexNum, ChunkSize*5)
+	}
+	if m.store.chunks[2].unverified || !m.store.chunks[3].unverified {
+		t.Errorf("unverified = %v, %v, want false, true", m.store.chunks[2].unverified, m.store.chunks[3].unverified)
+	}
+	if err := m.verifyChunk(3); !errors.Is(err, ErrInvalidIndex) {
+		t.Errorf("verifyChunk(3) = %v, want %v", err, ErrInvalidIndex)
+	}
+	path, err := indexPath(m.filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the invalid index is not removed: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package oviewer
+
+import (
+	"os"
+)
+
+// Dummy function because there is no inode in windows.
+func fileInode(fi os.FileInfo) uint64 {
+	return 0
+}
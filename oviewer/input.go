@@ -28,6 +28,14 @@ const (
 	MultiColor                 // MultiColor is multi-word coloring.
 	JumpTarget                 // JumpTarget is the position to display the search results.
 	SaveBuffer                 // SaveBuffer is the save buffer.
+	SortColumn                 // SortColumn is the sort type input mode.
+	ColumnBoundary             // ColumnBoundary is the column boundary input mode.
+	CharEncoding               // CharEncoding is the character encoding input mode.
+	RecordSep                  // RecordSep is the record separator input mode.
+	Signal                     // Signal is the signal to send to the command.
+	Stdin                      // Stdin is the line to write to the stdin of the command.
+	SetBookmark                // SetBookmark is the name and note of the bookmark.
+	GoBookmark                 // GoBookmark is the name of the bookmark to move.
 )
 
 // Input represents the status of various inputs.
@@ -37,18 +45,24 @@ type Input struct {
 
 	// Candidate is prepared when the history is used as an input candidate.
 	// Header and SkipLines use numbers up and down instead of candidate.
-	DelimiterCandidate    *candidate
-	ModeCandidate         *candidate
-	SearchCandidate       *candidate
-	GoCandidate           *candidate
-	TabWidthCandidate     *candidate
-	WatchCandidate        *candidate
-	WriteBACandidate      *candidate
-	SectionDelmCandidate  *candidate
-	SectionStartCandidate *candidate
-	MultiColorCandidate   *candidate
-	JumpTargetCandidate   *candidate
-	SaveBufferCandidate   *candidate
+	DelimiterCandidate       *candidate
+	ModeCandidate            *candidate
+	SearchCandidate          *candidate
+	GoCandidate              *candidate
+	TabWidthCandidate        *candidate
+	WatchCandidate           *candidate
+	WriteBACandidate         *candidate
+	SectionDelmCandidate     *candidate
+	SectionStartCandidate    *candidate
+	MultiColorCandidate      *candidate
+	JumpTargetCandidate      *candidate
+	SaveBufferCandidate      *candidate
+	SortCandidate            *candidate
+	EncodingCandidate        *candidate
+	RecordSeparatorCandidate *candidate
+	SignalCandidate          *candidate
+	StdinCandidate           *candidate
+	BookmarkCandidate        *candidate
 
 	value   string
 	cursorX int
@@ -70,6 +84,12 @@ func NewInput() *Input {
 	i.MultiColorCandidate = multiColorCandidate()
 	i.JumpTargetCandidate = jumpTargetCandidate()
 	i.SaveBufferCandidate = saveBufferCandidate()
+	i.SortCandidate = sortCandidate()
+	i.EncodingCandidate = encodingCandidate()
+	i.RecordSeparatorCandidate = recordSeparatorCandidate()
+	i.SignalCandidate = signalCandidate()
+	i.StdinCandidate = stdinCandidate()
+	i.BookmarkCandidate = bookmarkCandidate()
 
 	i.Event = &eventNormal{}
 	return &i
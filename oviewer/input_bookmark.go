@@ -0,0 +1,116 @@
+package oviewer
+
+import "github.com/gdamore/tcell/v2"
+
+// setBookmarkMode sets the inputMode to SetBookmark.
+func (root *Root) setBookmarkMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newSetBookmarkEvent(input.BookmarkCandidate)
+}
+
+// bookmarkCandidate returns the candidate to set to default.
+func bookmarkCandidate() *candidate {
+	return &candidate{
+		list: []string{},
+	}
+}
+
+// eventSetBookmark represents the set bookmark input mode.
+type eventSetBookmark struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newSetBookmarkEvent returns eventSetBookmark.
+func newSetBookmarkEvent(clist *candidate) *eventSetBookmark {
+	return &eventSetBookmark{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventSetBookmark) Mode() InputMode {
+	return SetBookmark
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventSetBookmark) Prompt() string {
+	return "Bookmark(name note):"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventSetBookmark) Confirm(str string) tcell.Event {
+	e.value = str
+	e.clist.list = toLast(e.clist.list, str)
+	e.clist.p = 0
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventSetBookmark) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventSetBookmark) Down(str string) string {
+	return e.clist.down()
+}
+
+// setGoBookmarkMode sets the inputMode to GoBookmark.
+// The names of the bookmarks of the current document are the candidates.
+func (root *Root) setGoBookmarkMode() {
+	m := root.Doc
+	if len(m.bookmarks) == 0 {
+		root.setMessage("no bookmarks")
+		return
+	}
+	names := make([]string, 0, len(m.bookmarks))
+	for _, b := range m.bookmarks {
+		names = append(names, b.Name)
+	}
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newGoBookmarkEvent(&candidate{list: names})
+}
+
+// eventGoBookmark represents the go to bookmark input mode.
+type eventGoBookmark struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newGoBookmarkEvent returns eventGoBookmark.
+func newGoBookmarkEvent(clist *candidate) *eventGoBookmark {
+	return &eventGoBookmark{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventGoBookmark) Mode() InputMode {
+	return GoBookmark
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventGoBookmark) Prompt() string {
+	return "Go to bookmark:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventGoBookmark) Confirm(str string) tcell.Event {
+	e.value = str
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventGoBookmark) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventGoBookmark) Down(str string) string {
+	return e.clist.down()
+}
@@ -0,0 +1,58 @@
+package oviewer
+
+import "github.com/gdamore/tcell/v2"
+
+// setColumnBoundaryMode sets the inputMode to ColumnBoundary.
+func (root *Root) setColumnBoundaryMode() {
+	if !root.Doc.ColumnWidth {
+		root.setMessage("column width mode is not enabled")
+		return
+	}
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+
+	clist := &candidate{
+		list: columnBoundaryList(root.Doc.columnWidths),
+	}
+	input.Event = newColumnBoundaryEvent(clist)
+}
+
+// eventColumnBoundary represents the column boundary input mode.
+type eventColumnBoundary struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newColumnBoundaryEvent returns columnBoundaryEvent.
+func newColumnBoundaryEvent(clist *candidate) *eventColumnBoundary {
+	return &eventColumnBoundary{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventColumnBoundary) Mode() InputMode {
+	return ColumnBoundary
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventColumnBoundary) Prompt() string {
+	return "Column boundary(add/remove):"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventColumnBoundary) Confirm(str string) tcell.Event {
+	e.value = str
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventColumnBoundary) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventColumnBoundary) Down(str string) string {
+	return e.clist.down()
+}
@@ -0,0 +1,59 @@
+package oviewer
+
+import "github.com/gdamore/tcell/v2"
+
+// setEncodingMode sets the inputMode to Encoding.
+func (root *Root) setEncodingMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newEncodingEvent(input.EncodingCandidate)
+}
+
+// encodingCandidate returns the candidate to set to default.
+func encodingCandidate() *candidate {
+	return &candidate{
+		list: EncodingNames(),
+	}
+}
+
+// eventEncoding represents the encoding input mode.
+type eventEncoding struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newEncodingEvent returns encodingEvent.
+func newEncodingEvent(clist *candidate) *eventEncoding {
+	return &eventEncoding{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventEncoding) Mode() InputMode {
+	return CharEncoding
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventEncoding) Prompt() string {
+	return "Encoding:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventEncoding) Confirm(str string) tcell.Event {
+	e.value = str
+	e.clist.list = toLast(e.clist.list, str)
+	e.clist.p = 0
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventEncoding) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventEncoding) Down(str string) string {
+	return e.clist.down()
+}
@@ -0,0 +1,64 @@
+package oviewer
+
+import "github.com/gdamore/tcell/v2"
+
+// setRecordSeparatorMode sets the inputMode to RecordSeparator.
+func (root *Root) setRecordSeparatorMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newRecordSeparatorEvent(input.RecordSeparatorCandidate)
+}
+
+// recordSeparatorCandidate returns the candidate to set to default.
+func recordSeparatorCandidate() *candidate {
+	return &candidate{
+		list: []string{
+			"cr",
+			"nul",
+			"crlf",
+			"lf",
+		},
+	}
+}
+
+// eventRecordSeparator represents the record separator input mode.
+type eventRecordSeparator struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newRecordSeparatorEvent returns recordSeparatorEvent.
+func newRecordSeparatorEvent(clist *candidate) *eventRecordSeparator {
+	return &eventRecordSeparator{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventRecordSeparator) Mode() InputMode {
+	return RecordSep
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventRecordSeparator) Prompt() string {
+	return "Record separator:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventRecordSeparator) Confirm(str string) tcell.Event {
+	e.value = str
+	e.clist.list = toLast(e.clist.list, str)
+	e.clist.p = 0
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventRecordSeparator) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventRecordSeparator) Down(str string) string {
+	return e.clist.down()
+}
@@ -0,0 +1,131 @@
+package oviewer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// signalNames is the signals that can be sent to the command.
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+// parseSignal parses the signal name (e.g. "INT", "SIGINT", "int") or number (e.g. "2").
+func parseSignal(str string) (syscall.Signal, error) {
+	str = strings.ToUpper(strings.TrimSpace(str))
+	if n, err := strconv.Atoi(str); err == nil {
+		for _, sig := range signalNames {
+			if int(sig) == n {
+				return sig, nil
+			}
+		}
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSignal, str)
+	}
+	if sig, ok := signalNames[strings.TrimPrefix(str, "SIG")]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrInvalidSignal, str)
+}
+
+// signalName returns the name of the signal (e.g. "SIGINT").
+func signalName(sig os.Signal) string {
+	for name, s := range signalNames {
+		if s == sig {
+			return "SIG" + name
+		}
+	}
+	return sig.String()
+}
+
+// setSignalMode sets the inputMode to Signal.
+func (root *Root) setSignalMode() {
+	if root.Doc.command == nil {
+		root.setMessagef("signal: %s", ErrNotCommand)
+		return
+	}
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newSignalEvent(input.SignalCandidate)
+}
+
+// signalCandidate returns the candidate to set to default.
+func signalCandidate() *candidate {
+	return &candidate{
+		list: []string{
+			"KILL",
+			"HUP",
+			"TERM",
+			"INT",
+		},
+	}
+}
+
+// eventSignal represents the signal input mode.
+type eventSignal struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newSignalEvent returns eventSignal.
+func newSignalEvent(clist *candidate) *eventSignal {
+	return &eventSignal{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventSignal) Mode() InputMode {
+	return Signal
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventSignal) Prompt() string {
+	return "Signal:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventSignal) Confirm(str string) tcell.Event {
+	e.value = str
+	e.clist.list = toLast(e.clist.list, str)
+	e.clist.p = 0
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventSignal) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventSignal) Down(str string) string {
+	return e.clist.down()
+}
+
+// sendSignal sends the signal to the command of the document.
+func (root *Root) sendSignal(input string) {
+	cmd := root.Doc.command
+	if cmd == nil {
+		root.setMessagef("signal: %s", ErrNotCommand)
+		return
+	}
+	sig, err := parseSignal(input)
+	if err != nil {
+		root.setMessagef("signal: %s", err)
+		return
+	}
+	if err := cmd.Signal(sig); err != nil {
+		root.setMessageLogf("signal: %s", err)
+		return
+	}
+	root.setMessagef("sent %s to %s", signalName(sig), cmd.args[0])
+}
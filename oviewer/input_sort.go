@@ -0,0 +1,77 @@
+package oviewer
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// setSortColumnMode sets the inputMode to SortColumn.
+func (root *Root) setSortColumnMode() {
+	if !root.Doc.ColumnMode {
+		root.setMessage("sort: column mode is not enabled")
+		return
+	}
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newSortColumnEvent(input.SortCandidate, root.Doc.columnCursor)
+}
+
+// sortCandidate returns the candidate to set to default.
+func sortCandidate() *candidate {
+	return &candidate{
+		list: []string{
+			"time desc",
+			"size desc",
+			"numeric desc",
+			"lexical desc",
+			"time",
+			"size",
+			"lexical",
+			"numeric",
+		},
+	}
+}
+
+// eventSortColumn represents the sort column input mode.
+type eventSortColumn struct {
+	tcell.EventTime
+	clist  *candidate
+	value  string
+	cursor int
+}
+
+// newSortColumnEvent returns sortColumnEvent.
+func newSortColumnEvent(clist *candidate, cursor int) *eventSortColumn {
+	return &eventSortColumn{clist: clist, cursor: cursor}
+}
+
+// Mode returns InputMode.
+func (e *eventSortColumn) Mode() InputMode {
+	return SortColumn
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventSortColumn) Prompt() string {
+	return fmt.Sprintf("Sort column %d by:", e.cursor)
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventSortColumn) Confirm(str string) tcell.Event {
+	e.value = str
+	e.clist.list = toLast(e.clist.list, str)
+	e.clist.p = 0
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventSortColumn) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventSortColumn) Down(str string) string {
+	return e.clist.down()
+}
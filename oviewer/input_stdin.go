@@ -0,0 +1,90 @@
+package oviewer
+
+import "github.com/gdamore/tcell/v2"
+
+// setStdinMode sets the inputMode to Stdin.
+func (root *Root) setStdinMode() {
+	if root.Doc.command == nil {
+		root.setMessagef("stdin: %s", ErrNotCommand)
+		return
+	}
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.Event = newStdinEvent(input.StdinCandidate)
+}
+
+// stdinCandidate returns the candidate to set to default.
+func stdinCandidate() *candidate {
+	return &candidate{
+		list: []string{},
+	}
+}
+
+// eventStdin represents the stdin input mode.
+type eventStdin struct {
+	tcell.EventTime
+	clist *candidate
+	value string
+}
+
+// newStdinEvent returns eventStdin.
+func newStdinEvent(clist *candidate) *eventStdin {
+	return &eventStdin{clist: clist}
+}
+
+// Mode returns InputMode.
+func (e *eventStdin) Mode() InputMode {
+	return Stdin
+}
+
+// Prompt returns the prompt string in the input field.
+func (e *eventStdin) Prompt() string {
+	return "stdin:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (e *eventStdin) Confirm(str string) tcell.Event {
+	e.value = str
+	e.clist.list = toLast(e.clist.list, str)
+	e.clist.p = 0
+	e.SetEventNow()
+	return e
+}
+
+// Up returns strings when the up key is pressed during input.
+func (e *eventStdin) Up(str string) string {
+	return e.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (e *eventStdin) Down(str string) string {
+	return e.clist.down()
+}
+
+// sendStdin writes the line to the stdin of the command of the document.
+// The empty line is also written.
+func (root *Root) sendStdin(input string) {
+	cmd := root.Doc.command
+	if cmd == nil {
+		root.setMessagef("stdin: %s", ErrNotCommand)
+		return
+	}
+	if err := cmd.WriteInput(input); err != nil {
+		root.setMessageLogf("stdin: %s", err)
+	}
+}
+
+// closeStdin closes the stdin of the command of the document (EOF).
+func (root *Root) closeStdin() {
+	cmd := root.Doc.command
+	if cmd == nil {
+		root.setMessagef("stdin: %s", ErrNotCommand)
+		return
+	}
+	if err := cmd.CloseInput(); err != nil {
+		root.setMessageLogf("stdin: %s", err)
+		return
+	}
+	root.setMessage("stdin closed")
+}
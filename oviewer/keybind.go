@@ -25,10 +25,18 @@ const (
 	actionRainbow        = "rainbow_mode"
 	actionCloseFile      = "close_file"
 	actionReload         = "reload"
+	actionRerun          = "rerun"
+	actionSignal         = "send_signal"
+	actionStdin          = "send_stdin"
+	actionCloseStdin     = "close_stdin"
 	actionWatch          = "watch"
 	actionWatchInterval  = "watch_interval"
+	actionWatchDiff      = "watch_diff"
+	actionWatchPrevious  = "watch_previous"
+	actionWatchNext      = "watch_next"
 	actionHelp           = "help"
 	actionLogDoc         = "logdoc"
+	actionMemoryUsage    = "memory_usage"
 	actionMoveDown       = "down"
 	actionMoveUp         = "up"
 	actionMoveTop        = "top"
@@ -49,6 +57,9 @@ const (
 	actionLastSection    = "last_section"
 	actionPrevSection    = "previous_section"
 	actionMark           = "mark"
+	actionSetBookmark    = "set_bookmark"
+	actionGoBookmark     = "goto_bookmark"
+	actionListBookmarks  = "list_bookmarks"
 	actionRemoveMark     = "remove_mark"
 	actionRemoveAllMark  = "remove_all_mark"
 	actionMoveMark       = "next_mark"
@@ -56,10 +67,14 @@ const (
 	actionViewMode       = "set_view_mode"
 	actionAlternate      = "alter_rows_mode"
 	actionLineNumMode    = "line_number_mode"
+	actionTimeDelta      = "time_delta_mode"
 	actionSearch         = "search"
 	actionWrap           = "wrap_mode"
 	actionColumnMode     = "column_mode"
 	actionColumnWidth    = "column_width"
+	actionColumnName     = "column_name"
+	actionColumnDetect   = "column_width_detect"
+	actionColumnBoundary = "column_boundary"
 	actionBackSearch     = "backsearch"
 	actionDelimiter      = "delimiter"
 	actionHeader         = "header"
@@ -75,6 +90,13 @@ const (
 	actionMultiColor     = "multi_color"
 	actionJumpTarget     = "jump_target"
 	actionSaveBuffer     = "save_buffer"
+	actionSortColumn     = "sort_column"
+	actionColumnStats    = "column_stats"
+	actionHexView        = "hex_view"
+	actionEncoding       = "set_encoding"
+	actionRecordSep      = "record_separator"
+	actionMerge          = "merge_documents"
+	actionJumpOrigin     = "jump_origin"
 
 	inputCaseSensitive      = "input_casesensitive"
 	inputSmartCaseSensitive = "input_smart_casesensitive"
@@ -101,11 +123,19 @@ func (root *Root) handlers() map[string]func() {
 		actionPlain:          root.togglePlain,
 		actionRainbow:        root.toggleRainbow,
 		actionReload:         root.Reload,
+		actionRerun:          root.rerun,
+		actionSignal:         root.setSignalMode,
+		actionStdin:          root.setStdinMode,
+		actionCloseStdin:     root.closeStdin,
 		actionWatch:          root.toggleWatch,
 		actionWatchInterval:  root.setWatchIntervalMode,
+		actionWatchDiff:      root.toggleWatchDiff,
+		actionWatchPrevious:  root.watchPrevious,
+		actionWatchNext:      root.watchNext,
 		actionCloseFile:      root.closeFile,
 		actionHelp:           root.helpDisplay,
 		actionLogDoc:         root.logDisplay,
+		actionMemoryUsage:    root.memoryUsageView,
 		actionMoveDown:       root.moveDownOne,
 		actionMoveUp:         root.moveUpOne,
 		actionMoveTop:        root.moveTop,
@@ -131,9 +161,16 @@ func (root *Root) handlers() map[string]func() {
 		actionWrap:           root.toggleWrapMode,
 		actionColumnMode:     root.toggleColumnMode,
 		actionColumnWidth:    root.toggleColumnWidth,
+		actionColumnName:     root.toggleColumnName,
+		actionColumnDetect:   root.detectColumnWidths,
+		actionColumnBoundary: root.setColumnBoundaryMode,
 		actionAlternate:      root.toggleAlternateRows,
 		actionLineNumMode:    root.toggleLineNumMode,
+		actionTimeDelta:      root.toggleTimeDelta,
 		actionMark:           root.addMark,
+		actionSetBookmark:    root.setBookmarkMode,
+		actionGoBookmark:     root.setGoBookmarkMode,
+		actionListBookmarks:  root.listBookmarks,
 		actionRemoveMark:     root.removeMark,
 		actionRemoveAllMark:  root.removeAllMark,
 		actionSearch:         root.setSearchMode,
@@ -152,6 +189,13 @@ func (root *Root) handlers() map[string]func() {
 		actionMultiColor:     root.setMultiColorMode,
 		actionJumpTarget:     root.setJumpTargetMode,
 		actionSaveBuffer:     root.setSaveBuffer,
+		actionSortColumn:     root.setSortColumnMode,
+		actionColumnStats:    root.sendColumnStats,
+		actionHexView:        root.hexView,
+		actionEncoding:       root.setEncodingMode,
+		actionRecordSep:      root.setRecordSeparatorMode,
+		actionMerge:          root.mergeDocuments,
+		actionJumpOrigin:     root.jumpOrigin,
 
 		inputCaseSensitive:      root.inputCaseSensitive,
 		inputSmartCaseSensitive: root.inputSmartCaseSensitive,
@@ -182,10 +226,18 @@ func defaultKeyBinds() KeyBind {
 		actionRainbow:        {"ctrl+r"},
 		actionCloseFile:      {"ctrl+F9", "ctrl+alt+s"},
 		actionReload:         {"F5", "ctrl+alt+l"},
+		actionRerun:          {"R"},
+		actionSignal:         {"alt+k"},
+		actionStdin:          {"alt+p"},
+		actionCloseStdin:     {"ctrl+alt+d"},
 		actionWatch:          {"F4", "ctrl+alt+w"},
 		actionWatchInterval:  {"ctrl+w"},
+		actionWatchDiff:      {"alt+w"},
+		actionWatchPrevious:  {"{"},
+		actionWatchNext:      {"}"},
 		actionHelp:           {"h", "ctrl+F1", "ctrl+alt+c"},
 		actionLogDoc:         {"ctrl+F2", "ctrl+alt+e"},
+		actionMemoryUsage:    {"ctrl+F4"},
 		actionMoveDown:       {"Enter", "Down", "ctrl+N"},
 		actionMoveUp:         {"Up", "ctrl+p"},
 		actionMoveTop:        {"Home"},
@@ -211,9 +263,16 @@ func defaultKeyBinds() KeyBind {
 		actionWrap:           {"w", "W"},
 		actionColumnMode:     {"c"},
 		actionColumnWidth:    {"alt+o"},
+		actionColumnName:     {"alt+n"},
+		actionColumnDetect:   {"alt+g"},
+		actionColumnBoundary: {"alt+b"},
 		actionAlternate:      {"C"},
 		actionLineNumMode:    {"G"},
+		actionTimeDelta:      {"T"},
 		actionMark:           {"m"},
+		actionSetBookmark:    {"alt+a"},
+		actionGoBookmark:     {"'"},
+		actionListBookmarks:  {"\""},
 		actionRemoveAllMark:  {"ctrl+delete"},
 		actionRemoveMark:     {"M"},
 		actionSearch:         {"/"},
@@ -233,6 +292,13 @@ func defaultKeyBinds() KeyBind {
 		actionMultiColor:     {"."},
 		actionJumpTarget:     {"j"},
 		actionSaveBuffer:     {"S"},
+		actionSortColumn:     {"o"},
+		actionColumnStats:    {"alt+t"},
+		actionHexView:        {"alt+x"},
+		actionEncoding:       {"alt+e"},
+		actionRecordSep:      {"alt+l"},
+		actionMerge:          {"alt+m"},
+		actionJumpOrigin:     {"alt+j"},
 
 		inputCaseSensitive:      {"alt+c"},
 		inputSmartCaseSensitive: {"alt+s"},
@@ -257,6 +323,7 @@ func (k KeyBind) String() string {
 	k.writeKeyBind(&b, actionSuspend, "suspend")
 	k.writeKeyBind(&b, actionHelp, "display help screen")
 	k.writeKeyBind(&b, actionLogDoc, "display log screen")
+	k.writeKeyBind(&b, actionMemoryUsage, "display memory usage")
 	k.writeKeyBind(&b, actionSync, "screen sync")
 	k.writeKeyBind(&b, actionFollow, "follow mode toggle")
 	k.writeKeyBind(&b, actionFollowAll, "follow all mode toggle")
@@ -279,17 +346,22 @@ func (k KeyBind) String() string {
 	k.writeKeyBind(&b, actionMoveHfRight, "scroll right half screen")
 	k.writeKeyBind(&b, actionMoveBeginLeft, "go to beginning of line")
 	k.writeKeyBind(&b, actionMoveEndRight, "go to end of line")
-	k.writeKeyBind(&b, actionGoLine, "go to line(input number or `.n` or `n%` allowed)")
+	k.writeKeyBind(&b, actionGoLine, "go to line(input number or `.n` or `n%` or time allowed)")
 
 	fmt.Fprint(&b, "\n\tMove document\n")
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionNextDoc, "next document")
 	k.writeKeyBind(&b, actionPreviousDoc, "previous document")
 	k.writeKeyBind(&b, actionCloseDoc, "close current document")
+	k.writeKeyBind(&b, actionMerge, "merge documents by timestamp")
+	k.writeKeyBind(&b, actionJumpOrigin, "jump to the original line")
 
 	fmt.Fprint(&b, "\n\tMark position\n")
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionMark, "mark current position")
+	k.writeKeyBind(&b, actionSetBookmark, "set a named bookmark with a note")
+	k.writeKeyBind(&b, actionGoBookmark, "move to the named bookmark")
+	k.writeKeyBind(&b, actionListBookmarks, "list bookmarks and marks")
 	k.writeKeyBind(&b, actionRemoveMark, "remove mark current position")
 	k.writeKeyBind(&b, actionRemoveAllMark, "remove all mark")
 	k.writeKeyBind(&b, actionMoveMark, "move to next marked position")
@@ -307,9 +379,13 @@ func (k KeyBind) String() string {
 	k.writeKeyBind(&b, actionWrap, "wrap/nowrap toggle")
 	k.writeKeyBind(&b, actionColumnMode, "column mode toggle")
 	k.writeKeyBind(&b, actionColumnWidth, "column width toggle")
+	k.writeKeyBind(&b, actionColumnName, "column name display toggle")
+	k.writeKeyBind(&b, actionColumnDetect, "detect column widths from the screen")
+	k.writeKeyBind(&b, actionColumnBoundary, "add/remove column boundaries")
 	k.writeKeyBind(&b, actionRainbow, "column rainbow toggle")
 	k.writeKeyBind(&b, actionAlternate, "alternate rows of style toggle")
 	k.writeKeyBind(&b, actionLineNumMode, "line number toggle")
+	k.writeKeyBind(&b, actionTimeDelta, "relative time toggle")
 	k.writeKeyBind(&b, actionPlain, "original decoration toggle(plain)")
 
 	fmt.Fprint(&b, "\n\tChange Display with Input\n")
@@ -321,6 +397,11 @@ func (k KeyBind) String() string {
 	k.writeKeyBind(&b, actionTabWidth, "TAB width")
 	k.writeKeyBind(&b, actionMultiColor, "multi color highlight")
 	k.writeKeyBind(&b, actionJumpTarget, "jump target(`.n` or `n%` or `section` allowed)")
+	k.writeKeyBind(&b, actionSortColumn, "sort by current column(lexical/numeric/size/time)")
+	k.writeKeyBind(&b, actionColumnStats, "statistics of current column")
+	k.writeKeyBind(&b, actionHexView, "hex dump view")
+	k.writeKeyBind(&b, actionEncoding, "character encoding of the document")
+	k.writeKeyBind(&b, actionRecordSep, "record separator of the document(lf/crlf/cr/nul)")
 
 	fmt.Fprint(&b, "\n\tSection\n")
 	fmt.Fprint(&b, "\n")
@@ -335,8 +416,15 @@ func (k KeyBind) String() string {
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionCloseFile, "close file")
 	k.writeKeyBind(&b, actionReload, "reload file")
+	k.writeKeyBind(&b, actionRerun, "rerun the command")
+	k.writeKeyBind(&b, actionSignal, "send a signal to the command")
+	k.writeKeyBind(&b, actionStdin, "write a line to stdin of the command")
+	k.writeKeyBind(&b, actionCloseStdin, "close stdin of the command (EOF)")
 	k.writeKeyBind(&b, actionWatch, "watch mode")
 	k.writeKeyBind(&b, actionWatchInterval, "set watch interval")
+	k.writeKeyBind(&b, actionWatchDiff, "highlight changes in watch mode toggle")
+	k.writeKeyBind(&b, actionWatchPrevious, "previous snapshot in watch mode")
+	k.writeKeyBind(&b, actionWatchNext, "next snapshot in watch mode")
 
 	fmt.Fprint(&b, "\n\tKey binding when typing\n")
 	fmt.Fprint(&b, "\n")
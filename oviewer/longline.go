@@ -0,0 +1,139 @@
+package oviewer
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// longLineSize is the number of bytes of a line that is converted lazily.
+// Only the part of a longer line that is displayed is converted to contents.
+const longLineSize = 64 * 1024
+
+// lazyLine holds the state of converting a long line.
+type lazyLine struct {
+	// state is the state of the parser at offset.
+	state *parseState
+	// str is the line limited by the limit.
+	str string
+	// lc is the converted contents.
+	lc contents
+	// offset is the number of bytes of str converted.
+	offset int
+	// tabWidth is the tab width used for conversion.
+	tabWidth int
+	// truncated is the number of bytes truncated by the limit.
+	truncated int
+}
+
+// limitLine returns the line limited to limit bytes and the number of bytes truncated.
+// If limit is 0 or less, it is unlimited.
+func limitLine(str string, limit int) (string, int) {
+	if limit <= 0 || len(str) <= limit {
+		return str, 0
+	}
+	n := limit
+	for n > 0 && !utf8.RuneStart(str[n]) {
+		n--
+	}
+	return str[:n], len(str) - n
+}
+
+// truncatedContents returns the contents that indicates that the line is truncated.
+func truncatedContents(size int) contents {
+	lc := parseString(fmt.Sprintf("…(line truncated %s)", formatMemorySize(int64(size))), 1)
+	style := tcell.StyleDefault.Foreground(tcell.ColorGray).Reverse(true)
+	for i := range lc {
+		lc[i].style = style
+	}
+	return lc
+}
+
+// newLazyLine returns lazyLine that has not converted anything yet.
+// The line is limited to limit bytes.
+func newLazyLine(str string, tabWidth int, limit int) *lazyLine {
+	str, truncated := limitLine(str, limit)
+	return &lazyLine{
+		state:     newParseState(),
+		str:       str,
+		lc:        make(contents, 0, min(len(str), longLineSize)),
+		tabWidth:  tabWidth,
+		truncated: truncated,
+	}
+}
+
+// parse converts the line until the contents reach width.
+func (l *lazyLine) parse(width int) {
+	if l.done() || len(l.lc) >= width {
+		return
+	}
+	lc, n := l.state.parse(l.lc, l.str[l.offset:], l.tabWidth, width)
+	l.lc = lc
+	l.offset += n
+}
+
+// done returns true if the entire line has been converted.
+func (l *lazyLine) done() bool {
+	return l.offset >= len(l.str)
+}
+
+// lineC returns LineC of the converted part.
+// The truncated indicator is added at the end of the converted line.
+func (l *lazyLine) lineC() LineC {
+	line := contentsToLineC(l.lc[:len(l.lc):len(l.lc)])
+	if !l.done() {
+		line.lazy = l
+		return line
+	}
+	if l.truncated > 0 {
+		line.lc = append(line.lc, truncatedContents(l.truncated)...)
+	}
+	return line
+}
+
+// strToLineC returns LineC from the string of one line.
+// Lines longer than longLineSize are converted up to width.
+func strToLineC(str string, tabWidth int, width int) LineC {
+	if len(str) > longLineSize {
+		l := newLazyLine(str, tabWidth, LineLimit)
+		l.parse(width)
+		return l.lineC()
+	}
+	str, truncated := limitLine(str, LineLimit)
+	line := newLineC(str, tabWidth)
+	if truncated > 0 {
+		line.lc = append(line.lc, truncatedContents(truncated)...)
+	}
+	return line
+}
+
+// lineWindow returns the width of contents required to display lines.
+// It covers the screen from the current horizontal position in no-wrap mode,
+// and the screen from the top of the wrapped line in wrap mode.
+func (m *Document) lineWindow() int {
+	return max(m.x, m.topLX) + max(m.width, 1)*(max(m.height, 0)+1)
+}
+
+// lineCWidth returns LineC whose contents are converted at least up to width.
+func (m *Document) lineCWidth(lN int, tabWidth int, width int) (LineC, error) {
+	if line, ok := m.cache.Get(lN); ok {
+		if line.lazy == nil || len(line.lc) >= width {
+			return line, nil
+		}
+		line.lazy.parse(width)
+		line = line.lazy.lineC()
+		m.cache.Add(lN, line)
+		return line, nil
+	}
+
+	if lN < 0 || lN >= m.BufEndNum() {
+		return LineC{}, ErrOutOfRange
+	}
+	str, err := m.LineStr(lN)
+	line := strToLineC(str, tabWidth, width)
+	if err == nil {
+		m.cache.Add(lN, line)
+	}
+	return line, err
+}
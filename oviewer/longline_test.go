@@ -0,0 +1,176 @@
+package oviewer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseState_parse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		str   string
+		limit int
+	}{
+		{name: "ascii", str: "abcdefghijklmnopqrstuvwxyz", limit: 5},
+		{name: "wide", str: "あいうえおかきくけこ", limit: 3},
+		{name: "tab", str: "a\tb\tc\td", limit: 4},
+		{name: "escape", str: "\x1b[31mred\x1b[m plain \x1b[1mbold\x1b[m", limit: 2},
+		{name: "combining", str: "éééé", limit: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			want := parseString(tt.str, 8)
+			state := newParseState()
+			var lc contents
+			offset := 0
+			for width := tt.limit; offset < len(tt.str); width += tt.limit {
+				var n int
+				lc, n = state.parse(lc, tt.str[offset:], 8, width)
+				offset += n
+			}
+			if !reflect.DeepEqual(lc, want) {
+				t.Errorf("parse() = %v, want %v", lc, want)
+			}
+		})
+	}
+}
+
+func Test_limitLine(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		str           string
+		limit         int
+		want          string
+		wantTruncated int
+	}{
+		{name: "unlimited", str: "abcdef", limit: 0, want: "abcdef"},
+		{name: "short", str: "abcdef", limit: 10, want: "abcdef"},
+		{name: "truncated", str: "abcdef", limit: 4, want: "abcd", wantTruncated: 2},
+		{name: "rune boundary", str: "aあいう", limit: 5, want: "aあ", wantTruncated: 6},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, truncated := limitLine(tt.str, tt.limit)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("limitLine() = %q, %d, want %q, %d", got, truncated, tt.want, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func Test_lazyLine(t *testing.T) {
+	t.Parallel()
+	str := strings.Repeat("0123456789", 10000)
+	l := newLazyLine(str, 8, 50000)
+	l.parse(100)
+	line := l.lineC()
+	if line.lazy == nil {
+		t.Fatal("lazy = nil, want not nil")
+	}
+	if len(line.lc) != 100 || line.str != str[:100] {
+		t.Errorf("lineC() = %d contents, want 100", len(line.lc))
+	}
+	l.parse(longLineSize)
+	line = l.lineC()
+	if line.lazy != nil {
+		t.Fatal("lazy != nil, want nil")
+	}
+	if line.str != str[:50000] {
+		t.Errorf("lineC() str length = %d, want 50000", len(line.str))
+	}
+	indicator, _ := ContentsToStr(line.lc[50000:])
+	if want := "…(line truncated 48.8KiB)"; indicator != want {
+		t.Errorf("indicator = %q, want %q", indicator, want)
+	}
+}
+
+func Test_strToLineCLimit(t *testing.T) {
+	defer func(limit int) { LineLimit = limit }(LineLimit)
+	LineLimit = 10
+	// Lines shorter than longLineSize are also limited.
+	line := strToLineC("0123456789abcdef", 8, 80)
+	if line.lazy != nil || line.str != "0123456789" {
+		t.Fatalf("strToLineC() = %q, want %q", line.str, "0123456789")
+	}
+	indicator, _ := ContentsToStr(line.lc[10:])
+	if want := "…(line truncated 6B)"; indicator != want {
+		t.Errorf("indicator = %q, want %q", indicator, want)
+	}
+	line = strToLineC("012345", 8, 80)
+	if line.str != "012345" || len(line.lc) != 6 {
+		t.Errorf("strToLineC() = %q %d contents, want %q", line.str, len(line.lc), "012345")
+	}
+}
+
+func TestDocument_longLine(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("0123456789", 100000) + "match"
+	if err := m.ReadAll(bytes.NewBufferString("first\n" + long + "\nlast\n")); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	m.width = 80
+	m.height = 23
+
+	line, valid := m.getLineC(1, m.TabWidth)
+	if !valid {
+		t.Fatal("getLineC() valid = false")
+	}
+	if line.lazy == nil || len(line.lc) != m.lineWindow() {
+		t.Fatalf("getLineC() = %d contents, want %d", len(line.lc), m.lineWindow())
+	}
+
+	// Move to the right in no-wrap mode.
+	m.x = 500000
+	line, _ = m.getLineC(1, m.TabWidth)
+	if len(line.lc) != m.lineWindow() {
+		t.Fatalf("getLineC() = %d contents, want %d", len(line.lc), m.lineWindow())
+	}
+	if got, _ := ContentsToStr(line.lc[m.x : m.x+10]); got != "0123456789" {
+		t.Errorf("contents at %d = %q", m.x, got)
+	}
+
+	// Convert to the end.
+	line, _ = m.getLineCWidth(1, m.TabWidth, len(long)+1)
+	if line.lazy != nil || line.str != long {
+		t.Errorf("getLineCWidth() = %d bytes, want %d", len(line.str), len(long))
+	}
+
+	line, _ = m.getLineC(2, m.TabWidth)
+	if line.lazy != nil || line.str != "last" {
+		t.Errorf("getLineC() = %q, want %q", line.str, "last")
+	}
+}
+
+func TestRoot_searchXPosLongLine(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("0123456789", 100000) + "match"
+	if err := m.ReadAll(bytes.NewBufferString(long + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	m.width = 80
+	m.height = 23
+	root := &Root{Doc: m, searcher: NewSearcher("match", nil, false, false)}
+	if got, want := root.searchXPos(0), 1000000; got != want {
+		t.Errorf("searchXPos() = %d, want %d", got, want)
+	}
+}
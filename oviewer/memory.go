@@ -0,0 +1,200 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// memoryUsed is the number of bytes of lines held in memory by all documents.
+var memoryUsed int64
+
+// budgetChunk is a chunk of a document in the memory budget.
+type budgetChunk struct {
+	s        *store
+	chunkNum int
+}
+
+// budgetChunks is the LRU of the chunks of all documents that can be read again from the file.
+// The oldest chunks are unloaded regardless of the document when MemoryBudget is exceeded.
+var budgetChunks = newBudgetChunks()
+
+// newBudgetChunks returns the LRU of the chunks in the memory budget.
+func newBudgetChunks() *lru.Cache[budgetChunk, struct{}] {
+	chunks, err := lru.New[budgetChunk, struct{}](math.MaxInt32)
+	if err != nil {
+		log.Panicf("lru new %s", err)
+	}
+	return chunks
+}
+
+// ParseMemorySize parses the size of the memory budget (e.g. 512M, 1.5GiB) and returns bytes.
+// Empty and 0 mean unlimited.
+func ParseMemorySize(str string) (int64, error) {
+	if strings.TrimSpace(str) == "" {
+		return 0, nil
+	}
+	size, ok := parseHumanSize(str)
+	if !ok || size < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidMemorySize, str)
+	}
+	return int64(size), nil
+}
+
+// formatMemorySize formats bytes as a human readable size.
+func formatMemorySize(size int64) string {
+	const units = "KMGTPE"
+	if size < 1024 {
+		return strconv.FormatInt(size, 10) + "B"
+	}
+	n := float64(size)
+	i := -1
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	return strconv.FormatFloat(n, 'f', 1, 64) + string(units[i]) + "iB"
+}
+
+// overBudget returns true if the memory used by all documents exceeds MemoryBudget.
+func overBudget() bool {
+	return MemoryBudget > 0 && atomic.LoadInt64(&memoryUsed) > MemoryBudget
+}
+
+// addBytes adds the number of bytes held in the chunk.
+// It is called with s.mu locked.
+func (s *store) addBytes(chunk *chunk, size int) {
+	chunk.bytes += int64(size)
+	atomic.AddInt64(&s.loadedBytes, int64(size))
+	atomic.AddInt64(&memoryUsed, int64(size))
+}
+
+// subBytes subtracts the number of bytes held in the chunk.
+// It is called with s.mu locked.
+func (s *store) subBytes(chunk *chunk) {
+	atomic.AddInt64(&s.loadedBytes, -chunk.bytes)
+	atomic.AddInt64(&memoryUsed, -chunk.bytes)
+	chunk.bytes = 0
+}
+
+// release subtracts the bytes of all chunks from the memory used,
+// because the store is no longer used.
+func (s *store) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for chunkNum, chunk := range s.chunks {
+		s.subBytes(chunk)
+		s.unmapChunk(chunk)
+		budgetChunks.Remove(budgetChunk{s: s, chunkNum: chunkNum})
+	}
+}
+
+// touchBudget marks the chunk of the file as the most recently used in the memory budget.
+func (s *store) touchBudget(chunkNum int) {
+	budgetChunks.Add(budgetChunk{s: s, chunkNum: chunkNum}, struct{}{})
+}
+
+// evictOverBudget unloads the least recently used chunks of all documents
+// while the memory budget is exceeded.
+// Only the chunks that can be read again from the file are unloaded,
+// and the chunk being loaded (chunkNum of s) is not unloaded.
+func evictOverBudget(s *store, chunkNum int) {
+	for overBudget() {
+		c, _, ok := budgetChunks.GetOldest()
+		if !ok || (c.s == s && c.chunkNum == chunkNum) {
+			return
+		}
+		// The chunk is being loaded by the other document.
+		if atomic.LoadInt32(&c.s.loading) == int32(c.chunkNum) {
+			return
+		}
+		c.s.unloadChunk(c.chunkNum)
+	}
+}
+
+// memoryUsage represents the memory usage of a document.
+type memoryUsage struct {
+	name   string
+	chunks int
+	loaded int
+	bytes  int64
+}
+
+// memoryUsage returns the memory usage of the document.
+func (m *Document) memoryUsage() memoryUsage {
+	name := m.FileName
+	if m.Caption != "" {
+		name = m.Caption
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	loaded := 0
+	for _, chunk := range m.store.chunks {
+		if len(chunk.lines) > 0 {
+			loaded++
+		}
+	}
+	return memoryUsage{
+		name:   name,
+		chunks: len(m.store.chunks),
+		loaded: loaded,
+		bytes:  atomic.LoadInt64(&m.store.loadedBytes),
+	}
+}
+
+// memoryUsageString returns the memory usage of the documents as a table.
+func memoryUsageString(docs []*Document) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "document\tchunks\tloaded\tbytes\t\n")
+	for _, doc := range docs {
+		u := doc.memoryUsage()
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t\n", u.name, u.chunks, u.loaded, formatMemorySize(u.bytes))
+	}
+	budget := "unlimited"
+	if MemoryBudget > 0 {
+		budget = formatMemorySize(MemoryBudget)
+	}
+	fmt.Fprintf(w, "total\t\t\t%s\t\n", formatMemorySize(atomic.LoadInt64(&memoryUsed)))
+	fmt.Fprintf(w, "budget\t\t\t%s\t\n", budget)
+	w.Flush()
+	return b.String()
+}
+
+// memoryUsageDocument returns a new document that displays the memory usage.
+func (root *Root) memoryUsageDocument() (*Document, error) {
+	root.mu.RLock()
+	str := memoryUsageString(root.DocList)
+	root.mu.RUnlock()
+
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = "memory usage"
+	doc.Caption = "(memory usage)"
+	doc.preventReload = true
+	if err := doc.ControlReader(bytes.NewBufferString(str), nil); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// memoryUsageView adds a document that displays the memory usage of the documents.
+func (root *Root) memoryUsageView() {
+	doc, err := root.memoryUsageDocument()
+	if err != nil {
+		root.setMessageLogf("memory usage: %s", err)
+		return
+	}
+	root.addDocument(doc)
+	doc.general.Header = 1
+	root.setMessagef("memory used %s", formatMemorySize(atomic.LoadInt64(&memoryUsed)))
+}
@@ -0,0 +1,198 @@
+package oviewer
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+func TestParseMemorySize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		str     string
+		want    int64
+		wantErr bool
+	}{
+		{name: "empty", str: "", want: 0},
+		{name: "bytes", str: "1000", want: 1000},
+		{name: "mega", str: "512M", want: 512 * 1024 * 1024},
+		{name: "gibi", str: "1.5GiB", want: 1536 * 1024 * 1024},
+		{name: "invalid", str: "lots", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseMemorySize(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemorySize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMemorySize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatMemorySize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		size int64
+		want string
+	}{
+		{name: "bytes", size: 100, want: "100B"},
+		{name: "kibi", size: 2048, want: "2.0KiB"},
+		{name: "mebi", size: 1536 * 1024, want: "1.5MiB"},
+		{name: "gibi", size: 3 * 1024 * 1024 * 1024, want: "3.0GiB"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatMemorySize(tt.size); got != tt.want {
+				t.Errorf("formatMemorySize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_store_loadedBytes(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := "0123456789\n"
+	if err := m.ReadAll(bytes.NewBufferString(strings.Repeat(line, 25000))); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	s := m.store
+	if got, want := atomic.LoadInt64(&s.loadedBytes), int64(len(line)*25000); got != want {
+		t.Fatalf("loadedBytes = %d, want %d", got, want)
+	}
+	s.setNewLoadChunks(loadChunksCapacity(false))
+	s.unloadChunk(1)
+	if got, want := atomic.LoadInt64(&s.loadedBytes), int64(len(line)*15000); got != want {
+		t.Errorf("loadedBytes after unload = %d, want %d", got, want)
+	}
+	if s.chunks[1].bytes != 0 {
+		t.Errorf("chunk bytes = %d, want 0", s.chunks[1].bytes)
+	}
+	u := m.memoryUsage()
+	if u.chunks != 3 || u.loaded != 2 {
+		t.Errorf("memoryUsage() = %+v, want 3 chunks and 2 loaded", u)
+	}
+	s.release()
+	if got := atomic.LoadInt64(&s.loadedBytes); got != 0 {
+		t.Errorf("loadedBytes after release = %d, want 0", got)
+	}
+}
+
+func Test_memoryUsageString(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.FileName = "test.txt"
+	if err := m.ReadAll(bytes.NewBufferString("a\nb\n")); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	lines := strings.Split(memoryUsageString([]*Document{m}), "\n")
+	if !strings.HasPrefix(lines[0], "document") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "test.txt 1 1 4B" {
+		t.Errorf("row = %q, want %q", got, "test.txt 1 1 4B")
+	}
+	if !strings.HasPrefix(lines[2], "total") || !strings.HasPrefix(lines[3], "budget") {
+		t.Errorf("footer = %q", lines[2:])
+	}
+}
+
+// budgetTestLoad loads the chunk counted as size bytes into the store.
+func budgetTestLoad(s *store, chunkNum int, size int, isFile bool) {
+	for len(s.chunks) <= chunkNum {
+		s.chunks = append(s.chunks, NewChunk(0))
+	}
+	if isFile {
+		s.swapLoadedFile(chunkNum)
+	} else {
+		s.loadChunksMem(chunkNum)
+	}
+	s.mu.Lock()
+	chunk := s.chunks[chunkNum]
+	chunk.lines = [][]byte{[]byte("a")}
+	s.addBytes(chunk, size)
+	s.mu.Unlock()
+	if isFile {
+		evictOverBudget(s, chunkNum)
+	} else {
+		s.evictChunksMem(chunkNum)
+	}
+}
+
+func Test_evictOverBudget(t *testing.T) {
+	defer func(budget int64, limit int, limitFile int) {
+		MemoryBudget, MemoryLimit, MemoryLimitFile = budget, limit, limitFile
+	}(MemoryBudget, MemoryLimit, MemoryLimitFile)
+	MemoryLimit, MemoryLimitFile = -1, 100
+	MemoryBudget = atomic.LoadInt64(&memoryUsed) + 300
+	// The chunks of the documents of other tests are not unloaded.
+	defer func(chunks *lru.Cache[budgetChunk, struct{}]) {
+		budgetChunks = chunks
+	}(budgetChunks)
+	budgetChunks = newBudgetChunks()
+
+	newStore := func(isFile bool) *store {
+		s := NewStore()
+		s.setNewLoadChunks(loadChunksCapacity(isFile))
+		return s
+	}
+	pipe := newStore(false)
+	fileA := newStore(true)
+	fileB := newStore(true)
+	defer func() {
+		for _, s := range []*store{pipe, fileA, fileB} {
+			s.release()
+		}
+	}()
+
+	budgetTestLoad(pipe, 1, 150, false)
+	budgetTestLoad(fileA, 1, 100, true)
+	// The oldest chunk of the other document is unloaded.
+	budgetTestLoad(fileB, 1, 100, true)
+	if len(fileA.chunks[1].lines) != 0 {
+		t.Errorf("the oldest chunk of the other file is not unloaded")
+	}
+	if len(fileB.chunks[1].lines) == 0 {
+		t.Errorf("the chunk being loaded is unloaded")
+	}
+
+	// The chunks of the non-regular file are not discarded by the usage of the other documents.
+	other := newStore(false)
+	defer other.release()
+	budgetTestLoad(other, 1, 200, false)
+	budgetTestLoad(pipe, 2, 10, false)
+	if len(pipe.chunks[1].lines) == 0 {
+		t.Errorf("the chunk of the non-regular file is discarded")
+	}
+	if len(fileB.chunks[1].lines) != 0 {
+		t.Errorf("the chunk of the file is not unloaded before the non-regular file")
+	}
+
+	// The chunks of the non-regular file are discarded if it alone exceeds the budget.
+	budgetTestLoad(pipe, 3, int(MemoryBudget), false)
+	if len(pipe.chunks[1].lines) != 0 {
+		t.Errorf("the oldest chunk of the non-regular file over the budget is not discarded")
+	}
+}
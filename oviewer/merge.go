@@ -0,0 +1,408 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// mergeInterval is the interval to read the lines added to the source documents.
+var mergeInterval = 500 * time.Millisecond
+
+// mergeTagColors is the SGR colors of the source tags.
+var mergeTagColors = []int{32, 33, 34, 35, 36, 31, 92, 93, 94, 95, 96, 91}
+
+// mergeSource is the source document of the merged document.
+type mergeSource struct {
+	doc *Document
+	// tag is the colored prefix added to the lines.
+	tag []byte
+	// next is the next line number to read.
+	next int
+	// last is the timestamp of the last line.
+	// Lines without a timestamp (e.g. stack traces) inherit it.
+	last time.Time
+	// grew is true if lines were added since the last update.
+	grew bool
+	// pending is the lines read but not merged yet.
+	pending []mergeLine
+}
+
+// mergeLine is a line of the source document with the timestamp.
+type mergeLine struct {
+	src  int
+	lN   int
+	time time.Time
+}
+
+// mergeOrigin is the position of the merged line in the source document.
+type mergeOrigin struct {
+	src int
+	lN  int
+}
+
+// mergeDocument interleaves the lines of the source documents by timestamp.
+// The merged document holds only the positions of the lines (origins),
+// and the lines are read from the source documents when they are displayed.
+// Lines are only appended, so a line added later with an earlier timestamp
+// is displayed after the lines that have already been merged.
+type mergeDocument struct {
+	doc        *Document
+	sources    []*mergeSource
+	timeReg    *regexp.Regexp
+	timeFormat string
+
+	// missed is 1 if a line was not in memory of the source document.
+	missed int32
+
+	mu      sync.RWMutex
+	origins []mergeOrigin
+}
+
+// newMergeDocument returns mergeDocument that merges the source documents.
+// timeReg is the regular expression that matches the timestamp (the first submatch is used if any),
+// and timeFormat is the layout of time.Parse. The known layouts are tried if timeFormat is empty.
+// The common timestamps of logs are detected if timeReg is empty.
+func newMergeDocument(docs []*Document, timeReg string, timeFormat string) (*mergeDocument, error) {
+	var reg *regexp.Regexp
+	if timeReg != "" {
+		var err error
+		if reg, err = regexp.Compile(timeReg); err != nil {
+			return nil, err
+		}
+	}
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	md := &mergeDocument{
+		doc:        doc,
+		timeReg:    reg,
+		timeFormat: timeFormat,
+	}
+
+	names := make([]string, 0, len(docs))
+	width := 0
+	for _, m := range docs {
+		name := filepath.Base(m.FileName)
+		names = append(names, name)
+		width = max(width, runewidth.StringWidth(name))
+	}
+	for n, m := range docs {
+		name := names[n] + strings.Repeat(" ", width-runewidth.StringWidth(names[n]))
+		color := mergeTagColors[n%len(mergeTagColors)]
+		md.sources = append(md.sources, &mergeSource{
+			doc: m,
+			tag: []byte(fmt.Sprintf("\x1b[%dm%s\x1b[0m ", color, name)),
+		})
+	}
+
+	doc.FileName = "merged"
+	doc.Caption = "(merge)" + strings.Join(names, " ")
+	doc.merge = md
+	doc.seekable = false
+	doc.preventReload = true
+	doc.store.view = md
+	if err := doc.ControlLog(); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+// lineTime returns the timestamp of the line.
+func (md *mergeDocument) lineTime(line []byte) (time.Time, bool) {
+	if md.timeReg == nil {
+		return lineTimestamp(line)
+	}
+	match := md.timeReg.FindSubmatch(line)
+	if match == nil {
+		return time.Time{}, false
+	}
+	str := string(match[0])
+	if len(match) > 1 {
+		str = string(match[1])
+	}
+	if md.timeFormat != "" {
+		t, err := time.Parse(md.timeFormat, str)
+		return t, err == nil
+	}
+	return parseTime(strings.Replace(str, ",", ".", 1))
+}
+
+// collect reads the timestamps of the lines added to the source documents.
+// The last line without a separator is not read because it may be still written.
+func (md *mergeDocument) collect(ctx context.Context) error {
+	for _, src := range md.sources {
+		src.grew = false
+		if src.doc.checkClose() {
+			continue
+		}
+		end := src.doc.BufEndNum()
+		if atomic.LoadInt32(&src.doc.store.noNewlineEOF) == 1 {
+			end--
+		}
+		if end <= src.next {
+			continue
+		}
+		n := len(src.pending)
+		err := src.doc.rangeLines(ctx, src.next, end, func(lN int, line []byte) error {
+			if t, ok := md.lineTime(line); ok {
+				src.last = t
+			}
+			src.pending = append(src.pending, mergeLine{lN: lN, time: src.last})
+			return nil
+		})
+		if err != nil {
+			src.pending = src.pending[:n]
+			return err
+		}
+		src.next = end
+		src.grew = true
+	}
+	return nil
+}
+
+// watermark returns the timestamp up to which the lines can be merged.
+// A source that is still being read may have lines earlier than the lines of the others,
+// so the lines after its last timestamp are held.
+// ok is false if all lines can be merged.
+func (md *mergeDocument) watermark() (limit time.Time, ok bool) {
+	for _, src := range md.sources {
+		if !src.grew || src.doc.BufEOF() || src.doc.checkClose() || src.last.IsZero() {
+			continue
+		}
+		if !ok || src.last.Before(limit) {
+			limit, ok = src.last, true
+		}
+	}
+	return limit, ok
+}
+
+// mergeLines merges the lines of each source in order of timestamp.
+// The order of the lines in the same source is kept,
+// and the lines of the same time are in order of the sources.
+// If limited is true, the lines after limit are not merged.
+func mergeLines(lines [][]mergeLine, limit time.Time, limited bool) []mergeLine {
+	total := 0
+	for _, l := range lines {
+		total += len(l)
+	}
+	merged := make([]mergeLine, 0, total)
+	heads := make([]int, len(lines))
+	for len(merged) < total {
+		first := -1
+		for n, l := range lines {
+			if heads[n] >= len(l) {
+				continue
+			}
+			if first < 0 || l[heads[n]].time.Before(lines[first][heads[first]].time) {
+				first = n
+			}
+		}
+		line := lines[first][heads[first]]
+		if limited && line.time.After(limit) {
+			break
+		}
+		line.src = first
+		merged = append(merged, line)
+		heads[first]++
+	}
+	return merged
+}
+
+// update appends the lines added to the source documents to the merged document.
+// The added lines are merged with each other,
+// and are appended after the lines that have already been merged.
+func (md *mergeDocument) update(ctx context.Context) error {
+	if err := md.collect(ctx); err != nil {
+		return err
+	}
+	s := md.doc.store
+	if atomic.SwapInt32(&md.missed, 0) == 1 {
+		// Redraw the lines read into memory of the source documents.
+		atomic.StoreInt32(&s.changed, 1)
+	}
+
+	lines := make([][]mergeLine, len(md.sources))
+	for n, src := range md.sources {
+		lines[n] = src.pending
+	}
+	limit, limited := md.watermark()
+	merged := mergeLines(lines, limit, limited)
+	if len(merged) == 0 {
+		atomic.StoreInt32(&s.eof, 1)
+		return nil
+	}
+
+	origins := make([]mergeOrigin, 0, len(merged))
+	taken := make([]int, len(md.sources))
+	for _, l := range merged {
+		origins = append(origins, mergeOrigin{src: l.src, lN: l.lN})
+		taken[l.src]++
+	}
+	for n, src := range md.sources {
+		src.pending = append([]mergeLine(nil), src.pending[taken[n]:]...)
+	}
+
+	md.mu.Lock()
+	md.origins = append(md.origins, origins...)
+	endNum := len(md.origins)
+	md.mu.Unlock()
+
+	// The chunks have no lines, but are needed for the number of lines.
+	s.mu.Lock()
+	for len(s.chunks)*ChunkSize < endNum {
+		s.chunks = append(s.chunks, &chunk{})
+	}
+	s.mu.Unlock()
+	atomic.StoreInt32(&s.endNum, int32(endNum))
+	atomic.StoreInt32(&s.eof, 1)
+	atomic.StoreInt32(&s.changed, 1)
+	return nil
+}
+
+// viewLine returns the merged line read from the source document.
+// If the line is not in memory of the source document,
+// it is requested to be loaded and ErrNotLoaded is returned.
+func (md *mergeDocument) viewLine(lN int) ([]byte, error) {
+	md.mu.RLock()
+	if lN < 0 || lN >= len(md.origins) {
+		md.mu.RUnlock()
+		return nil, fmt.Errorf("%w %d", ErrOutOfRange, lN)
+	}
+	o := md.origins[lN]
+	md.mu.RUnlock()
+
+	src := md.sources[o.src]
+	m := src.doc
+	chunkNum, cn := chunkLineNum(o.lN)
+	if !m.store.isLoadedChunk(chunkNum, m.seekable) {
+		atomic.StoreInt32(&md.missed, 1)
+		m.requestLoad(chunkNum)
+		return nil, fmt.Errorf("%w %d", ErrNotLoaded, chunkNum)
+	}
+	line, err := m.store.GetChunkLine(chunkNum, cn)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(src.tag)+len(line))
+	buf = append(buf, src.tag...)
+	return append(buf, line...), nil
+}
+
+// run updates the merged document until it is closed.
+func (md *mergeDocument) run() {
+	ctx := context.Background()
+	ticker := time.NewTicker(mergeInterval)
+	defer ticker.Stop()
+	for {
+		if md.doc.checkClose() {
+			return
+		}
+		if err := md.update(ctx); err != nil {
+			log.Printf("merge: %s", err)
+		}
+		<-ticker.C
+	}
+}
+
+// origin returns the source document and the line number of the merged line.
+func (md *mergeDocument) origin(lN int) (*Document, int, bool) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+	if lN < 0 || lN >= len(md.origins) {
+		return nil, 0, false
+	}
+	o := md.origins[lN]
+	return md.sources[o.src].doc, o.lN, true
+}
+
+// mergeDocuments adds a document that merges the documents by timestamp.
+func (root *Root) mergeDocuments() {
+	root.mu.RLock()
+	docs := mergeSources(root.DocList)
+	root.mu.RUnlock()
+	if len(docs) < 2 {
+		root.setMessage("merge: there are less than two documents")
+		return
+	}
+
+	md, err := newMergeDocument(docs, root.Config.MergeTimeRegexp, root.Config.MergeTimeFormat)
+	if err != nil {
+		root.setMessageLogf("merge: %s", err)
+		return
+	}
+	for _, m := range docs {
+		m.startDeferred()
+	}
+	root.addDocument(md.doc)
+	go md.run()
+}
+
+// mergeSources returns the documents to be merged.
+// Documents generated by ov (merged, sorted, help, statistics, etc.) are excluded,
+// but the members of an archive are merged.
+func mergeSources(docList []*Document) []*Document {
+	var docs []*Document
+	for _, m := range docList {
+		if m.merge != nil || m.parent != nil {
+			continue
+		}
+		if m.preventReload && !m.archived {
+			continue
+		}
+		docs = append(docs, m)
+	}
+	return docs
+}
+
+// jumpOrigin displays the original document at the position of the current line.
+// The current line of the merged document jumps to the source document,
+// and the current line of the derived document (e.g. sorted) jumps to the parent document.
+func (root *Root) jumpOrigin() {
+	m := root.Doc
+	lN := m.topLN + m.firstLine()
+	var src *Document
+	var srcLN int
+	switch {
+	case m.merge != nil:
+		var ok bool
+		if src, srcLN, ok = m.merge.origin(lN); !ok {
+			root.setMessage("no original line")
+			return
+		}
+	case m.parent != nil:
+		src, srcLN = m.parent, m.originLN(lN)
+	default:
+		root.setMessage("not a merged or derived document")
+		return
+	}
+
+	num := -1
+	root.mu.RLock()
+	for n, doc := range root.DocList {
+		if doc == src {
+			num = n
+		}
+	}
+	root.mu.RUnlock()
+	if num < 0 {
+		root.setMessagef("%s has been closed", src.FileName)
+		return
+	}
+
+	// Following would move the position to the bottom,
+	// so the lines read so far are regarded as displayed.
+	src.FollowMode = false
+	src.latestNum = src.BufEndNum()
+	root.setDocumentNum(num)
+	root.goLineNumber(srcLN)
+}
@@ -0,0 +1,251 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_mergeLines(t *testing.T) {
+	t.Parallel()
+	at := func(sec int) time.Time {
+		return time.Date(2024, 1, 1, 0, 0, sec, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		lines [][]mergeLine
+		limit time.Time
+		want  []mergeOrigin
+	}{
+		{
+			name: "interleave",
+			lines: [][]mergeLine{
+				{{src: 0, lN: 0, time: at(1)}, {src: 0, lN: 1, time: at(3)}},
+				{{src: 1, lN: 0, time: at(2)}, {src: 1, lN: 1, time: at(4)}},
+			},
+			want: []mergeOrigin{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+		},
+		{
+			name: "same time in order of sources",
+			lines: [][]mergeLine{
+				{{src: 0, lN: 0, time: at(1)}},
+				{{src: 1, lN: 0, time: at(1)}},
+			},
+			want: []mergeOrigin{{0, 0}, {1, 0}},
+		},
+		{
+			name: "keep the order of the source",
+			lines: [][]mergeLine{
+				{{src: 0, lN: 0, time: at(5)}, {src: 0, lN: 1, time: at(1)}},
+				{{src: 1, lN: 0, time: at(3)}},
+			},
+			want: []mergeOrigin{{1, 0}, {0, 0}, {0, 1}},
+		},
+		{
+			name: "limit",
+			lines: [][]mergeLine{
+				{{src: 0, lN: 0, time: at(1)}, {src: 0, lN: 1, time: at(3)}},
+				{{src: 1, lN: 0, time: at(2)}, {src: 1, lN: 1, time: at(4)}},
+			},
+			limit: at(2),
+			want:  []mergeOrigin{{0, 0}, {1, 0}},
+		},
+		{
+			name:  "empty",
+			lines: [][]mergeLine{nil, nil},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []mergeOrigin
+			for _, l := range mergeLines(tt.lines, tt.limit, !tt.limit.IsZero()) {
+				got = append(got, mergeOrigin{src: l.src, lN: l.lN})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDocument_lineTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		timeReg    string
+		timeFormat string
+		line       string
+		want       time.Time
+		wantOK     bool
+	}{
+		{
+			name:   "rfc3339",
+			line:   "2024-01-02T03:04:05Z INFO start",
+			want:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "comma milliseconds",
+			line:   "2024-01-02 03:04:05,250 WARN slow",
+			want:   time.Date(2024, 1, 2, 3, 4, 5, 250000000, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "access log",
+			line:   `127.0.0.1 - - [02/Jan/2024:03:04:05 +0000] "GET / HTTP/1.1" 200`,
+			want:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:       "format",
+			timeReg:    `^\[(\d+)\]`,
+			timeFormat: "20060102150405",
+			line:       "[20240102030405] start",
+			want:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			wantOK:     true,
+		},
+		{
+			name:   "no timestamp",
+			line:   "\tat main.main()",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			md := &mergeDocument{timeFormat: tt.timeFormat}
+			if tt.timeReg != "" {
+				md.timeReg = regexp.MustCompile(tt.timeReg)
+			}
+			got, ok := md.lineTime([]byte(tt.line))
+			if ok != tt.wantOK {
+				t.Fatalf("lineTime() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("lineTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDocument_update(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app.log")
+	db := filepath.Join(dir, "db.log")
+	if err := os.WriteFile(app, []byte("2024-01-01 00:00:01 app start\n2024-01-01 00:00:03 app error\n\tat main()\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(db, []byte("2024-01-01 00:00:02 db start\n2024-01-01 00:00:04 db stop\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sources := []*Document{openIndexTestDocument(t, app), openIndexTestDocument(t, db)}
+	md, err := newMergeDocument(sources, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := md.update(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		line string
+		src  *Document
+		lN   int
+	}{
+		{line: "\x1b[32mapp.log\x1b[0m 2024-01-01 00:00:01 app start", src: sources[0], lN: 0},
+		{line: "\x1b[33mdb.log \x1b[0m 2024-01-01 00:00:02 db start", src: sources[1], lN: 0},
+		{line: "\x1b[32mapp.log\x1b[0m 2024-01-01 00:00:03 app error", src: sources[0], lN: 1},
+		{line: "\x1b[32mapp.log\x1b[0m \tat main()", src: sources[0], lN: 2},
+		{line: "\x1b[33mdb.log \x1b[0m 2024-01-01 00:00:04 db stop", src: sources[1], lN: 1},
+	}
+	if got := md.doc.BufEndNum(); got != len(want) {
+		t.Fatalf("BufEndNum() = %d, want %d", got, len(want))
+	}
+	for lN, w := range want {
+		if got := md.doc.LineString(lN); got != w.line {
+			t.Errorf("line %d = %q, want %q", lN, got, w.line)
+		}
+		src, srcLN, ok := md.origin(lN)
+		if !ok || src != w.src || srcLN != w.lN {
+			t.Errorf("origin(%d) = %p:%d, want %p:%d", lN, src, srcLN, w.src, w.lN)
+		}
+	}
+
+	w := &bytes.Buffer{}
+	if err := md.doc.Export(w, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.String(); got != want[0].line+"\n"+want[1].line+"\n" {
+		t.Errorf("Export() = %q", got)
+	}
+
+	// The lines added to the source are appended.
+	sources[1].FollowMode = true
+	appendTestFile(t, db, "2024-01-01 00:00:05 db start\n")
+	rotateTestFollow(t, sources[1], 3)
+	if err := md.update(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := md.doc.LineString(len(want)); got != "\x1b[33mdb.log \x1b[0m 2024-01-01 00:00:05 db start" {
+		t.Errorf("appended line = %q", got)
+	}
+	if _, srcLN, _ := md.origin(len(want)); srcLN != 2 {
+		t.Errorf("origin(%d) = %d, want 2", len(want), srcLN)
+	}
+}
+
+func TestMergeDocument_watermark(t *testing.T) {
+	t.Parallel()
+	at := func(sec int) time.Time {
+		return time.Date(2024, 1, 1, 0, 0, sec, 0, time.UTC)
+	}
+	reading := func() *Document {
+		m, err := NewDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	eof := reading()
+	eof.store.eof = 1
+	md := &mergeDocument{
+		sources: []*mergeSource{
+			{doc: reading(), grew: true, last: at(5)},
+			{doc: reading(), grew: true, last: at(3)},
+			// Stopped growing, such as a pipe waiting for input.
+			{doc: reading(), grew: false, last: at(1)},
+			{doc: eof, grew: true, last: at(2)},
+		},
+	}
+	limit, ok := md.watermark()
+	if !ok || !limit.Equal(at(3)) {
+		t.Errorf("watermark() = %v, %v, want %v, true", limit, ok, at(3))
+	}
+	md.sources = md.sources[2:]
+	if _, ok := md.watermark(); ok {
+		t.Errorf("watermark() ok = true, want false")
+	}
+}
+
+func Test_mergeSources(t *testing.T) {
+	t.Parallel()
+	file := &Document{}
+	member := &Document{preventReload: true, archived: true}
+	sorted := &Document{parent: file, preventReload: true}
+	help := &Document{preventReload: true}
+	merged := &Document{merge: &mergeDocument{}, preventReload: true}
+	got := mergeSources([]*Document{file, member, sorted, help, merged})
+	want := []*Document{file, member}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeSources() = %d documents, want %d", len(got), len(want))
+	}
+}
@@ -0,0 +1,211 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// mmapFile is a regular file read by mapping it into memory.
+// The range of the chunk is mapped when the chunk is loaded,
+// the lines of the chunk refer to the mapped region,
+// and it is unmapped when the chunk is unloaded or the document is closed.
+type mmapFile struct {
+	file *os.File
+	// size is the size of the file when it was mapped last.
+	size int64
+	// failed is 1 if the file can no longer be mapped
+	// (e.g. the file has been truncated).
+	failed int32
+}
+
+// newMmapFile returns mmapFile that maps the file when a chunk is loaded.
+func newMmapFile(file *os.File) *mmapFile {
+	return &mmapFile{file: file}
+}
+
+// isFailed returns true if the file can no longer be mapped.
+func (f *mmapFile) isFailed() bool {
+	return atomic.LoadInt32(&f.failed) == 1
+}
+
+// fail stops mapping the file.
+func (f *mmapFile) fail() {
+	atomic.StoreInt32(&f.failed, 1)
+}
+
+// mapRange maps the file from start to end.
+// It returns the mapped region and the offset of start in the region,
+// because the offset of the mapping must be a multiple of the page size.
+// An error is returned if the file has been truncated.
+func (f *mmapFile) mapRange(start int64, end int64) ([]byte, int, error) {
+	if f.isFailed() {
+		return nil, 0, ErrMmapFault
+	}
+	fi, err := f.file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := fi.Size()
+	if size < f.size {
+		f.fail()
+		return nil, 0, fmt.Errorf("%w: truncated to %d", ErrMmapFault, size)
+	}
+	f.size = size
+	if end > size {
+		return nil, 0, fmt.Errorf("%w: %d is beyond the end of the file", ErrOutOfRange, end)
+	}
+	if end <= start {
+		return nil, 0, fmt.Errorf("%w: empty range", ErrOutOfRange)
+	}
+
+	offset := start &^ int64(os.Getpagesize()-1)
+	data, err := mmap(f.file, offset, int(end-offset))
+	if err != nil {
+		f.fail()
+		return nil, 0, err
+	}
+	return data, int(start - offset), nil
+}
+
+// faultSafe calls fn and returns ErrMmapFault
+// if fn faults on accessing the mapped region (e.g. the file has been truncated).
+func faultSafe(fn func()) (err error) {
+	old := debug.SetPanicOnFault(true)
+	defer func() {
+		debug.SetPanicOnFault(old)
+		if r := recover(); r != nil {
+			if _, ok := r.(interface{ Addr() uintptr }); !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("%w: %v", ErrMmapFault, r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// splitRecords splits the region into records including the separator.
+// The records refer to the region.
+func splitRecords(data []byte, sep byte, lines [][]byte) [][]byte {
+	for len(data) > 0 {
+		n := bytes.IndexByte(data, sep) + 1
+		if n == 0 {
+			n = len(data)
+		}
+		lines = append(lines, data[:n:n])
+		data = data[n:]
+	}
+	return lines
+}
+
+// loadChunkMmap loads the chunk from the mapped range of the file instead of reading it.
+// It returns false if the chunk cannot be loaded from the mapped file.
+// The first and the last chunk are always read,
+// because they are read before mapping and may be appended.
+func (m *Document) loadChunkMmap(chunkNum int) bool {
+	s := m.store
+	if s.mmap == nil || chunkNum == 0 {
+		return false
+	}
+	s.mu.RLock()
+	last := len(s.chunks) - 1
+	chunk := s.chunks[chunkNum]
+	var end int64
+	if chunkNum < last {
+		end = s.chunks[chunkNum+1].start
+	}
+	s.mu.RUnlock()
+	if chunkNum >= last {
+		return false
+	}
+
+	data, offset, err := s.mmap.mapRange(chunk.start, end)
+	if err != nil {
+		log.Printf("mmap: %s", err)
+		return false
+	}
+	sep, _ := s.separator()
+	var lines [][]byte
+	if err := faultSafe(func() {
+		lines = splitRecords(data[offset:], sep, make([][]byte, 0, ChunkSize))
+	}); err != nil {
+		s.mmap.fail()
+		unmapRegion(data)
+		log.Printf("mmap: %s", err)
+		return false
+	}
+	if len(lines) != ChunkSize {
+		unmapRegion(data)
+		log.Printf("mmap: chunk %d has %d lines", chunkNum, len(lines))
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unmapChunk(chunk)
+	s.subBytes(chunk)
+	chunk.lines = lines
+	chunk.mapped = data
+	return true
+}
+
+// unmapChunk unmaps the region of the chunk.
+// The lines of the chunk are dropped, because they refer to the region.
+// s.mu must be locked.
+func (s *store) unmapChunk(chunk *chunk) {
+	if chunk.mapped == nil {
+		return
+	}
+	chunk.lines = nil
+	unmapRegion(chunk.mapped)
+	chunk.mapped = nil
+}
+
+// isStaleMapped returns true if the chunk refers to the file
+// that can no longer be mapped, so it must be read again.
+func (s *store) isStaleMapped(chunkNum int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if chunkNum >= len(s.chunks) || s.chunks[chunkNum].mapped == nil {
+		return false
+	}
+	return s.mmap == nil || s.mmap.isFailed()
+}
+
+// unmapRegion unmaps the region and logs the error.
+func unmapRegion(data []byte) {
+	if err := munmap(data); err != nil {
+		log.Printf("munmap: %s", err)
+	}
+}
+
+// mappedLine returns a copy of the line that refers to the mapped file.
+// ErrMmapFault is returned if the file can no longer be accessed,
+// and the chunk is read again from the next request.
+// s.mu must be locked.
+func (s *store) mappedLine(line []byte) ([]byte, error) {
+	if s.mmap == nil || s.mmap.isFailed() {
+		return nil, ErrMmapFault
+	}
+	var buf []byte
+	if err := faultSafe(func() {
+		buf = append(make([]byte, 0, len(line)), line...)
+	}); err != nil {
+		s.mmap.fail()
+		return nil, err
+	}
+	return trimSeparator(buf, s.sep, s.crlf), nil
+}
+
+// failMmap stops mapping the file after a fault.
+func (s *store) failMmap() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.mmap != nil {
+		s.mmap.fail()
+	}
+}
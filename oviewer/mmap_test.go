@@ -0,0 +1,150 @@
+package oviewer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_splitRecords(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data string
+		sep  byte
+		want []string
+	}{
+		{name: "lf", data: "a\nbc\n\n", sep: '\n', want: []string{"a\n", "bc\n", "\n"}},
+		{name: "no separator at the end", data: "a\nbc", sep: '\n', want: []string{"a\n", "bc"}},
+		{name: "nul", data: "a\x00b\nc\x00", sep: 0, want: []string{"a\x00", "b\nc\x00"}},
+		{name: "empty", data: "", sep: '\n', want: nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for _, line := range splitRecords([]byte(tt.data), tt.sep, nil) {
+				got = append(got, string(line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitRecords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func mmapTestFile(t *testing.T, lines int) string {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), "mmap.txt")
+	var b strings.Builder
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestDocument_mmap(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mmap is not supported")
+	}
+	mmapMode := Mmap
+	Mmap = true
+	defer func() {
+		Mmap = mmapMode
+	}()
+
+	m := openIndexTestDocument(t, mmapTestFile(t, 35000))
+	defer m.store.release()
+	if m.store.mmap == nil {
+		t.Fatal("mmap = nil, want not nil")
+	}
+	tests := []struct {
+		lN         int
+		wantMapped bool
+	}{
+		{lN: 15000, wantMapped: true},
+		{lN: 25000, wantMapped: true},
+		{lN: 34999, wantMapped: false},
+		{lN: 19999, wantMapped: true},
+	}
+	for _, tt := range tests {
+		chunkNum := tt.lN / ChunkSize
+		if got := m.loadChunkMmap(chunkNum); got != tt.wantMapped {
+			t.Errorf("loadChunkMmap(%d) = %v, want %v", chunkNum, got, tt.wantMapped)
+		}
+		want := fmt.Sprintf("line %d", tt.lN)
+		if got := indexTestLine(t, m, tt.lN); got != want {
+			t.Errorf("line %d = %q, want %q", tt.lN, got, want)
+		}
+	}
+	// The lines refer to the mapped file, so the bytes are not held in memory.
+	chunk := m.store.chunks[1]
+	if chunk.mapped == nil {
+		t.Fatal("mapped = nil, want the mapped region")
+	}
+	if chunk.bytes != 0 {
+		t.Errorf("chunk bytes = %d, want 0", chunk.bytes)
+	}
+
+	w := &bytes.Buffer{}
+	if err := m.Export(w, 19998, 19999); err != nil {
+		t.Fatal(err)
+	}
+	if want := "line 19998\nline 19999\n"; w.String() != want {
+		t.Errorf("Export() = %q, want %q", w.String(), want)
+	}
+
+	// The region is unmapped when the chunk is unloaded.
+	m.store.unloadChunk(1)
+	if chunk.mapped != nil || chunk.lines != nil {
+		t.Errorf("unloadChunk() mapped = %v, lines = %d, want nil", chunk.mapped != nil, len(chunk.lines))
+	}
+}
+
+func TestDocument_mmapTruncated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mmap is not supported")
+	}
+	mmapMode := Mmap
+	Mmap = true
+	defer func() {
+		Mmap = mmapMode
+	}()
+
+	fileName := mmapTestFile(t, 35000)
+	m := openIndexTestDocument(t, fileName)
+	defer m.store.release()
+	if got := indexTestLine(t, m, 15000); got != "line 15000" {
+		t.Fatalf("line 15000 = %q", got)
+	}
+	if err := os.Truncate(fileName, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mapped lines fault and the chunk is read again.
+	if _, err := m.store.GetChunkLine(1, 5000); !errors.Is(err, ErrMmapFault) {
+		t.Errorf("GetChunkLine() error = %v, want %v", err, ErrMmapFault)
+	}
+	if !m.store.mmap.isFailed() {
+		t.Errorf("isFailed() = false, want true")
+	}
+	if !m.store.isStaleMapped(1) {
+		t.Errorf("isStaleMapped() = false, want true")
+	}
+	if m.store.isLoadedChunk(1, true) {
+		t.Errorf("isLoadedChunk() = true, want false")
+	}
+	if m.loadChunkMmap(2) {
+		t.Errorf("loadChunkMmap() = true, want false")
+	}
+}
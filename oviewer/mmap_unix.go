@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package oviewer
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap maps the range of the file into memory read-only.
+func mmap(file *os.File, offset int64, length int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), offset, length, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmap unmaps the region mapped by mmap.
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
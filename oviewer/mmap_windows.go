@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package oviewer
+
+import (
+	"os"
+)
+
+// mmap is not supported in windows, so the file is read instead.
+func mmap(file *os.File, offset int64, length int) ([]byte, error) {
+	return nil, ErrNotSupportedMmap
+}
+
+// Dummy function because mmap is not supported in windows.
+func munmap(data []byte) error {
+	return nil
+}
@@ -159,7 +159,7 @@ func (m *Document) moveToWidth(scr SCR, moveTo int) (int, int, error) {
 		cl = widths[len(widths)-1]
 		cr = m.rightmost(scr)
 	}
-	return screenAdjustX(m.x, m.x+m.width, cl, cr, widths, cursor)
+	return m.adjustX(m.width, cl, cr, widths, cursor)
 }
 
 // moveToDelimiter returns x and cursor from the orientation to move.
@@ -188,11 +188,11 @@ func (m *Document) moveToDelimiter(moveTo int) (int, int, error) {
 			if cursor < len(widths)-1 {
 				cr = line.pos.x(widths[cursor+1])
 			}
-			return screenAdjustX(m.x, m.x+width, cl, cr, widths, cursor)
+			return m.adjustX(width, cl, cr, widths, cursor)
 		} else {
 			cl := line.pos.x(widths[len(widths)-1])
 			cr := line.pos.x(len(line.str))
-			return screenAdjustX(m.x, m.x+width, cl, cr, widths, cursor)
+			return m.adjustX(width, cl, cr, widths, cursor)
 		}
 	}
 
@@ -202,6 +202,38 @@ func (m *Document) moveToDelimiter(moveTo int) (int, int, error) {
 	return 0, m.columnCursor, ErrNoDelimiter
 }
 
+// adjustX returns x and cursor when moving left and right.
+// The header columns are always displayed,
+// so they are excluded from the range to scroll.
+func (m *Document) adjustX(width int, cl int, cr int, widths []int, cursor int) (int, int, error) {
+	hx := m.headerColumnWidth()
+	if hx == 0 || hx >= width {
+		return screenAdjustX(m.x, m.x+width, cl, cr, widths, cursor)
+	}
+	if cursor < m.HeaderColumn {
+		return m.x, cursor, nil
+	}
+	x, cursor, err := screenAdjustX(m.x+hx, m.x+width, cl, cr, widths, cursor)
+	return max(0, x-hx), cursor, err
+}
+
+// headerColumnWidth returns the width of the header columns of the lines on the screen.
+func (m *Document) headerColumnWidth() int {
+	if !m.ColumnMode || m.WrapMode || m.HeaderColumn <= 0 {
+		return 0
+	}
+	for i := 0; i < TargetLineDelimiter; i++ {
+		line, valid := m.getLineC(m.topLN+m.firstLine()+i, m.TabWidth)
+		if !valid {
+			continue
+		}
+		if x := m.headerColumnX(line); x > 0 {
+			return x
+		}
+	}
+	return 0
+}
+
 // screenAdjustX returns x and cursor when moving left and right.
 // If it moves too much, adjust the position and return.
 // Returns an error when the end is reached.
@@ -359,11 +391,11 @@ func (m *Document) endRight(scr SCR) int {
 func (m *Document) rightmost(scr SCR) int {
 	maxLen := 0
 	for _, line := range scr.numbers {
-		lc, err := m.contents(line.number, m.TabWidth)
-		if err != nil {
+		lineC, valid := m.getLineC(line.number, m.TabWidth)
+		if !valid {
 			continue
 		}
-		maxLen = max(maxLen, len(lc)-1)
+		maxLen = max(maxLen, len(lineC.lc)-1)
 	}
 	return maxLen
 }
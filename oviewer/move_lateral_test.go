@@ -694,3 +694,105 @@ func TestDocument_moveColumnDelimiterLeft(t *testing.T) {
 		})
 	}
 }
+
+func TestDocument_moveColumnHeaderColumn(t *testing.T) {
+	type fields struct {
+		cursor int
+		x      int
+	}
+	type args struct {
+		n int
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		wantCursor int
+		wantX      int
+	}{
+		{
+			name: "rightNoScroll",
+			fields: fields{
+				cursor: 1,
+				x:      0,
+			},
+			args: args{
+				n: 1,
+			},
+			wantCursor: 2,
+			wantX:      0,
+		},
+		{
+			name: "rightScroll",
+			fields: fields{
+				cursor: 4,
+				x:      0,
+			},
+			args: args{
+				n: 1,
+			},
+			wantCursor: 5,
+			wantX:      13,
+		},
+		{
+			name: "leftScroll",
+			fields: fields{
+				cursor: 3,
+				x:      9,
+			},
+			args: args{
+				n: -1,
+			},
+			wantCursor: 2,
+			wantX:      0,
+		},
+		{
+			name: "leftHeaderColumn",
+			fields: fields{
+				cursor: 1,
+				x:      22,
+			},
+			args: args{
+				n: -1,
+			},
+			wantCursor: 0,
+			wantX:      22,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := moveColumnDelimiter(t)
+			m.ColumnMode = true
+			m.WrapMode = false
+			m.HeaderColumn = 2
+			m.width = 40
+			m.columnCursor = tt.fields.cursor
+			m.x = tt.fields.x
+			numbers := make([]LineNumber, m.height)
+			for i := 0; i < m.height; i++ {
+				numbers[i] = LineNumber{
+					number: i,
+					wrap:   1,
+				}
+			}
+			scr := SCR{
+				numbers: numbers,
+			}
+			var err error
+			if tt.args.n > 0 {
+				err = m.moveColumnRight(tt.args.n, scr, false)
+			} else {
+				err = m.moveColumnLeft(-tt.args.n, scr, false)
+			}
+			if err != nil {
+				t.Errorf("Document.moveColumn() error = %v", err)
+			}
+			if m.columnCursor != tt.wantCursor {
+				t.Errorf("Document.moveColumn() cursor = %v, want %v", m.columnCursor, tt.wantCursor)
+			}
+			if m.x != tt.wantX {
+				t.Errorf("Document.moveColumn() x = %v, want %v", m.x, tt.wantX)
+			}
+		})
+	}
+}
@@ -31,7 +31,7 @@ func (m *Document) moveTop() {
 // moveBottom moves to the bottom.
 func (m *Document) moveBottom() {
 	// If the file is seekable, move to the end of the file.
-	if m.seekable && atomic.LoadInt32(&m.store.eof) == 0 && atomic.LoadInt32(&m.tmpFollow) == 0 {
+	if m.tailReadable() && atomic.LoadInt32(&m.store.eof) == 0 && atomic.LoadInt32(&m.tmpFollow) == 0 {
 		m.requestBottom()
 	}
 
@@ -220,11 +220,11 @@ func (m *Document) moveYUp(moveY int) {
 // leftMostX returns a list of left - most x positions when wrapping.
 // Returns nil if there is no line number.
 func (m *Document) leftMostX(lN int) []int {
-	lc, err := m.contents(lN, m.TabWidth)
-	if err != nil {
+	line, valid := m.getLineC(lN, m.TabWidth)
+	if !valid {
 		return nil
 	}
-	return leftX(m.width, lc)
+	return leftX(m.width, line.lc)
 }
 
 // leftX returns a list of left - most x positions when wrapping.
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"syscall"
 
 	"code.rocketnine.space/tslocum/cbind"
@@ -49,6 +50,8 @@ type Root struct {
 	message string
 	// cancelKeys represents the cancellation key string.
 	cancelKeys []string
+	// hexViewKeys represents the key string of the hex view.
+	hexViewKeys []string
 
 	// DocList is the list of documents.
 	DocList []*Document
@@ -72,6 +75,13 @@ type Root struct {
 	// mu controls the RWMutex.
 	mu sync.RWMutex
 
+	// followGlobs is the absolute patterns of FollowGlob.
+	followGlobs []string
+	// globOpened is the files opened by FollowGlob.
+	globOpened map[string]bool
+	// globWaiting is the document displayed until a file matches FollowGlob.
+	globWaiting *Document
+
 	// skipDraw is set to true when the mouse cursor just moves (no event occurs).
 	skipDraw bool
 	// mousePressed is a flag when the mouse selection button is pressed.
@@ -93,6 +103,8 @@ type SCR struct {
 	vHeight int
 	// startX is the start position of x.
 	startX int
+	// timeX is the start position of x of the relative time.
+	timeX int
 }
 
 // LineNumber is Number of logical lines and number of wrapping lines on the screen.
@@ -123,12 +135,22 @@ type general struct {
 	Header int
 	// SkipLines is the rows to skip.
 	SkipLines int
+	// HeaderColumn is number of columns to be fixed at the left edge.
+	HeaderColumn int
 	// WatchInterval is the watch interval (seconds).
 	WatchInterval int
 	// MarkStyleWidth is width to apply the style of the marked line.
 	MarkStyleWidth int
 	// SectionStartPosition is a section start position.
 	SectionStartPosition int
+	// ColumnWidthScanLines is the number of lines to scan to guess the column widths.
+	ColumnWidthScanLines int
+	// ColumnWidthMinLines is the minimum number of lines
+	// that must be blank at the same position to be a column boundary.
+	ColumnWidthMinLines int
+	// ColumnWidthSeparator is the minimum number of spaces
+	// in the header to be a column boundary.
+	ColumnWidthSeparator int
 	// AlternateRows alternately style rows.
 	AlternateRows bool
 	// ColumnMode is column mode.
@@ -137,8 +159,14 @@ type general struct {
 	ColumnWidth bool
 	// ColumnRainbow is column rainbow.
 	ColumnRainbow bool
+	// ColumnName displays the column name of the cursor in the status line.
+	ColumnName bool
 	// LineNumMode displays line numbers.
 	LineNumMode bool
+	// TimeDelta displays the relative time from the previous line.
+	TimeDelta bool
+	// WatchDiff highlights the changes from the previous snapshot in watch mode.
+	WatchDiff bool
 	// Wrap is Wrap mode.
 	WrapMode bool
 	// FollowMode is the follow mode.
@@ -149,6 +177,9 @@ type general struct {
 	FollowSection bool
 	// FollowName is the mode to follow files by name.
 	FollowName bool
+	// KeepRotated keeps the content of the rotated file and appends a rotation marker
+	// instead of reading the file again in follow mode.
+	KeepRotated bool
 	// PlainMode is whether to enable the original character decoration.
 	PlainMode bool
 }
@@ -202,6 +233,8 @@ type Config struct {
 	StyleSectionLine OVStyle
 	// StyleJumpTargetLine is the line that displays the search results.
 	StyleJumpTargetLine OVStyle
+	// StyleWatchDiff is the style that applies to the changes from the previous snapshot in watch mode.
+	StyleWatchDiff OVStyle
 	// StyleAlternate is a style that applies line by line.
 	StyleAlternate OVStyle
 	// StyleOverStrike is a style that applies to overstrike.
@@ -220,6 +253,34 @@ type Config struct {
 	MemoryLimit int
 	// MemoryLimitFile is a number that limits the chunks loading a file into memory.
 	MemoryLimitFile int
+	// MemoryBudget is the size of memory (e.g. 512M) that all documents can hold.
+	MemoryBudget string
+	// LineLimit is the size of a line (e.g. 4M) to display.
+	LineLimit string
+	// Mmap maps regular files into memory instead of reading chunks.
+	Mmap bool
+	// IndexCache saves the line index of files in the cache directory.
+	IndexCache bool
+	// WatchHistory is the number of snapshots to compare and step back in watch mode.
+	WatchHistory int
+	// StopOnError stops watch mode when the executed command exits with a non-zero status.
+	StopOnError bool
+	// Pty runs the executed command under a pty (linux only).
+	Pty bool
+	// Combined adds the document that interleaves stdout and stderr of the executed command.
+	Combined bool
+	// ExecStdin connects the stdin of the executed command to a pipe to write lines from ov.
+	ExecStdin bool
+	// FollowGlob is the patterns of files to open when they are created.
+	FollowGlob []string
+	// CloseRemoved closes the documents of the removed files in FollowGlob.
+	CloseRemoved bool
+	// Merge displays the document that merges all documents by timestamp.
+	Merge bool
+	// MergeTimeRegexp is a regular expression that matches the timestamp of the merged lines.
+	MergeTimeRegexp string
+	// MergeTimeFormat is the layout of the timestamp of the merged lines (e.g. 2006-01-02 15:04:05).
+	MergeTimeFormat string
 	// Mouse support disable.
 	DisableMouse bool
 	// IsWriteOriginal is true, write the current screen on quit.
@@ -286,6 +347,17 @@ var (
 	MemoryLimit int
 	// MemoryLimitFile is a number that limits the chunks loading a file into memory.
 	MemoryLimitFile int
+	// MemoryBudget is the number of bytes of lines that all documents can hold in memory.
+	// 0 is unlimited.
+	MemoryBudget int64
+	// LineLimit is the number of bytes of a line to display.
+	// The rest of a longer line is truncated. 0 is unlimited.
+	LineLimit int
+	// Mmap maps regular files into memory instead of reading chunks.
+	Mmap bool
+	// WatchHistory is the number of snapshots to compare and step back in watch mode.
+	// 0 is unlimited.
+	WatchHistory int
 
 	// OverStrikeStyle represents the overstrike style.
 	OverStrikeStyle tcell.Style
@@ -293,6 +365,17 @@ var (
 	OverLineStyle tcell.Style
 	// SkipExtract is a flag to skip extracting compressed files.
 	SkipExtract bool
+	// CompressFormat is the format to extract files instead of detecting it.
+	// If empty, the format is detected from the contents and the file name.
+	CompressFormat string
+	// RecordSeparator is the separator of records (lf, crlf, cr, nul or a character).
+	// If empty, records are separated by newlines.
+	RecordSeparator string
+	// Encoding is the character encoding of the file instead of detecting it.
+	// If empty or "auto", the encoding is detected from the contents.
+	Encoding string
+	// IndexCache is a flag to save the line index of files in the cache directory.
+	IndexCache bool
 )
 
 // ov output destination.
@@ -369,6 +452,56 @@ var (
 	ErrAlreadyLoaded = errors.New("chunk already loaded")
 	// ErrEvictedMemory indicates that it has been evicted from memory.
 	ErrEvictedMemory = errors.New("evicted memory")
+	// ErrInvalidSortType indicates that the sort type is invalid.
+	ErrInvalidSortType = errors.New("invalid sort type")
+	// ErrInvalidIndex indicates that the index cache does not match the file.
+	ErrInvalidIndex = errors.New("invalid index")
+	// ErrSeekCompressed indicates that the compressed file cannot be seeked from the end.
+	ErrSeekCompressed = errors.New("cannot seek from the end of compressed file")
+	// ErrInvalidZstdFrame indicates that the zstd frame is invalid.
+	ErrInvalidZstdFrame = errors.New("invalid zstd frame")
+	// ErrUnknownCompress indicates that the compression format is unknown.
+	ErrUnknownCompress = errors.New("unknown compression format")
+	// ErrInvalidLzip indicates that the lzip data is invalid.
+	ErrInvalidLzip = errors.New("invalid lzip data")
+	// ErrNotArchive indicates that the file is not an archive.
+	ErrNotArchive = errors.New("not an archive")
+	// ErrNoTextMember indicates that the archive has no text member.
+	ErrNoTextMember = errors.New("no text member in the archive")
+	// ErrNoHexSource indicates that the document has no source for the hex dump.
+	ErrNoHexSource = errors.New("no source for hex dump")
+	// ErrNotEOF indicates that the end of the document has not been read yet.
+	ErrNotEOF = errors.New("not read to the end yet")
+	// ErrUnknownEncoding indicates that the character encoding is unknown.
+	ErrUnknownEncoding = errors.New("unknown encoding")
+	// ErrSeekEncoding indicates that the decoded data cannot be seeked.
+	ErrSeekEncoding = errors.New("cannot seek the decoded data")
+	// ErrInvalidSeparator indicates that the record separator is invalid.
+	ErrInvalidSeparator = errors.New("invalid record separator")
+	// ErrInvalidMemorySize indicates that the memory size is invalid.
+	ErrInvalidMemorySize = errors.New("invalid memory size")
+	// ErrMmapFault indicates that the mapped file cannot be accessed.
+	ErrMmapFault = errors.New("cannot access the mapped file")
+	// ErrNotSupportedMmap indicates that mmap is not supported.
+	ErrNotSupportedMmap = errors.New("mmap is not supported")
+	// ErrNotSupportedPty indicates that pty is not supported.
+	ErrNotSupportedPty = errors.New("pty is not supported")
+	// ErrNotCommand indicates that the document is not the output of the command.
+	ErrNotCommand = errors.New("not the output of the command")
+	// ErrCommandExited indicates that the command has already exited.
+	ErrCommandExited = errors.New("command has already exited")
+	// ErrNoStdin indicates that the stdin of the command is not available.
+	ErrNoStdin = errors.New("stdin of the command is not available")
+	// ErrStdinBusy indicates that the input waiting to be written to the command is full.
+	ErrStdinBusy = errors.New("the command is not reading the stdin")
+	// ErrInvalidSignal indicates that the signal is invalid.
+	ErrInvalidSignal = errors.New("invalid signal")
+	// ErrInvalidBookmark indicates that the bookmark is invalid.
+	ErrInvalidBookmark = errors.New("invalid bookmark")
+	// ErrInvalidTime indicates that the time is invalid.
+	ErrInvalidTime = errors.New("invalid time")
+	// ErrNoTimestamp indicates that no line has a timestamp.
+	ErrNoTimestamp = errors.New("no timestamp")
 )
 
 // This is a function of tcell.NewScreen but can be replaced with mock.
@@ -416,6 +549,7 @@ func NewRoot(r io.Reader) (*Root, error) {
 	if err != nil {
 		return nil, err
 	}
+	m.setDefaultSeparator()
 	if err := m.ControlReader(r, nil); err != nil {
 		return nil, err
 	}
@@ -449,12 +583,13 @@ func openSTDIN() (*Root, error) {
 
 // openFile creates root in one file.
 // If there is only one file, an error will occur if the file fails to open.
+// An archive opens each text member.
 func openFile(fileName string) (*Root, error) {
-	m, err := OpenDocument(fileName)
+	docs, err := openDocuments(fileName)
 	if err != nil {
 		return nil, err
 	}
-	return NewOviewer(m)
+	return NewOviewer(docs...)
 }
 
 // openFiles opens multiple files and creates root.
@@ -463,12 +598,12 @@ func openFiles(fileNames []string) (*Root, error) {
 	errors := make([]string, 0)
 	docList := make([]*Document, 0)
 	for _, fileName := range fileNames {
-		m, err := OpenDocument(fileName)
+		docs, err := openDocuments(fileName)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("open error: %s", err))
 			continue
 		}
-		docList = append(docList, m)
+		docList = append(docList, docs...)
 	}
 
 	if len(docList) == 0 {
@@ -496,6 +631,22 @@ func (root *Root) SetConfig(config Config) {
 
 // SetWatcher sets file monitoring.
 func (root *Root) SetWatcher(watcher *fsnotify.Watcher) {
+	for _, doc := range root.DocList {
+		fileName, err := filepath.Abs(doc.FileName)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		doc.filepath = fileName
+
+		path := filepath.Dir(fileName)
+		if err := watcher.Add(path); err != nil {
+			root.debugMessage(fmt.Sprintf("watcher %s:%s", doc.FileName, err))
+		}
+	}
+	root.watchGlob(watcher)
+
+	// Events are handled after the watched files and patterns are set.
 	go func() {
 		for {
 			select {
@@ -512,26 +663,12 @@ func (root *Root) SetWatcher(watcher *fsnotify.Watcher) {
 			}
 		}
 	}()
-
-	for _, doc := range root.DocList {
-		fileName, err := filepath.Abs(doc.FileName)
-		if err != nil {
-			log.Println(err)
-			continue
-		}
-		doc.filepath = fileName
-
-		path := filepath.Dir(fileName)
-		if err := watcher.Add(path); err != nil {
-			root.debugMessage(fmt.Sprintf("watcher %s:%s", doc.FileName, err))
-		}
-	}
 }
 
 // watchEvent sends a notification to the document.
+// The events are handled one by one in the goroutine of the watcher.
 func (root *Root) watchEvent(event fsnotify.Event) {
 	root.mu.Lock()
-	defer root.mu.Unlock()
 
 	for _, m := range root.DocList {
 		if m.filepath == event.Name {
@@ -539,12 +676,20 @@ func (root *Root) watchEvent(event fsnotify.Event) {
 			case fsnotify.Write:
 				root.sendRequest(m, requestFollow)
 			case fsnotify.Remove, fsnotify.Create:
-				if m.FollowName {
-					root.sendRequest(m, requestReload)
+				if !m.FollowName {
+					continue
+				}
+				// The rotation is detected when following.
+				if m.KeepRotated {
+					root.sendRequest(m, requestFollow)
+					continue
 				}
+				root.sendRequest(m, requestReload)
 			}
 		}
 	}
+	root.mu.Unlock()
+	root.globEvent(event)
 }
 
 func (root *Root) sendRequest(m *Document, request request) {
@@ -569,6 +714,7 @@ func (root *Root) setKeyConfig() (map[string][]string, error) {
 	} else {
 		root.cancelKeys = keys
 	}
+	root.hexViewKeys = keyBind[actionHexView]
 	return keyBind, nil
 }
 
@@ -609,7 +755,7 @@ func (root *Root) Run() error {
 		doc.general = root.Config.General
 		doc.regexpCompile()
 
-		if doc.FollowName {
+		if doc.FollowName || root.matchGlob(doc.filepath) {
 			doc.FollowMode = true
 		}
 		if doc.ColumnWidth {
@@ -622,6 +768,9 @@ func (root *Root) Run() error {
 		}
 		log.Printf("open [%d]%s%s", n, doc.FileName, w)
 	}
+	if root.Config.Merge {
+		root.mergeDocuments()
+	}
 
 	root.ViewSync()
 	// Exit if fits on screen
@@ -814,6 +963,9 @@ func mergeGeneral(src general, dst general) general {
 	if dst.SkipLines != 0 {
 		src.SkipLines = dst.SkipLines
 	}
+	if dst.HeaderColumn != 0 {
+		src.HeaderColumn = dst.HeaderColumn
+	}
 	if dst.AlternateRows {
 		src.AlternateRows = dst.AlternateRows
 	}
@@ -826,9 +978,18 @@ func mergeGeneral(src general, dst general) general {
 	if dst.ColumnRainbow {
 		src.ColumnRainbow = dst.ColumnRainbow
 	}
+	if dst.ColumnName {
+		src.ColumnName = dst.ColumnName
+	}
 	if dst.LineNumMode {
 		src.LineNumMode = dst.LineNumMode
 	}
+	if dst.TimeDelta {
+		src.TimeDelta = dst.TimeDelta
+	}
+	if dst.WatchDiff {
+		src.WatchDiff = dst.WatchDiff
+	}
 	if !dst.WrapMode { // Because wrap mode defaults to true.
 		src.WrapMode = dst.WrapMode
 	}
@@ -844,6 +1005,9 @@ func mergeGeneral(src general, dst general) general {
 	if dst.FollowName {
 		src.FollowName = dst.FollowName
 	}
+	if dst.KeepRotated {
+		src.KeepRotated = dst.KeepRotated
+	}
 	if dst.ColumnDelimiter != "" {
 		src.ColumnDelimiter = dst.ColumnDelimiter
 	}
@@ -859,6 +1023,15 @@ func mergeGeneral(src general, dst general) general {
 	if dst.SectionStartPosition != 0 {
 		src.SectionStartPosition = dst.SectionStartPosition
 	}
+	if dst.ColumnWidthScanLines != 0 {
+		src.ColumnWidthScanLines = dst.ColumnWidthScanLines
+	}
+	if dst.ColumnWidthMinLines != 0 {
+		src.ColumnWidthMinLines = dst.ColumnWidthMinLines
+	}
+	if dst.ColumnWidthSeparator != 0 {
+		src.ColumnWidthSeparator = dst.ColumnWidthSeparator
+	}
 	if dst.JumpTarget != "" {
 		src.JumpTarget = dst.JumpTarget
 	}
@@ -892,12 +1065,16 @@ func (root *Root) docSmall() bool {
 	}
 	height := 0
 	for y := 0; y < m.BufEndNum(); y++ {
-		lc, err := m.contents(y, root.Doc.TabWidth)
-		if err != nil {
-			log.Printf("docSmall %d: %s", y, err)
+		line, valid := m.getLineC(y, root.Doc.TabWidth)
+		if !valid {
+			log.Printf("docSmall %d: out of range", y)
 			continue
 		}
-		height += 1 + (len(lc) / root.scr.vWidth)
+		// A long line that has not been converted to the end does not fit.
+		if line.lazy != nil {
+			return false
+		}
+		height += 1 + (len(line.lc) / root.scr.vWidth)
 		if height > root.scr.vHeight {
 			return false
 		}
@@ -958,6 +1135,7 @@ func (root *Root) debugNumOfChunk() {
 	}
 	log.Println("MemoryLimit:", root.MemoryLimit)
 	log.Println("MemoryLimitFile:", root.MemoryLimitFile)
+	log.Println("MemoryBudget:", MemoryBudget, "used:", atomic.LoadInt64(&memoryUsed))
 	for _, doc := range root.DocList {
 		if !doc.seekable {
 			if MemoryLimit > 0 {
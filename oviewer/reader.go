@@ -29,8 +29,19 @@ const FormFeed = "\f"
 // Fill the contents of the read file into the first chunk.
 func (m *Document) firstRead(reader *bufio.Reader) (*bufio.Reader, error) {
 	atomic.StoreInt32(&m.store.noNewlineEOF, 0)
+	if idx, appended := m.loadIndex(); idx != nil {
+		if m.validIndex(idx) {
+			return m.indexRead(reader, idx, appended)
+		}
+		log.Printf("index: %s is invalid", m.FileName)
+		if err := removeIndex(m.filepath); err != nil {
+			log.Println(err)
+		}
+	}
 	chunk := m.store.chunks[0]
-	if err := m.store.readLines(chunk, reader, 0, ChunkSize, true); err != nil {
+	err := m.store.readLines(chunk, reader, 0, ChunkSize, true)
+	m.detectBinary()
+	if err != nil {
 		if errors.Is(err, io.EOF) {
 			return m.afterEOF(reader), nil
 		}
@@ -42,10 +53,36 @@ func (m *Document) firstRead(reader *bufio.Reader) (*bufio.Reader, error) {
 	return reader, nil
 }
 
+// detectBinary checks whether the beginning of the first chunk looks like binary.
+func (m *Document) detectBinary() {
+	m.store.mu.RLock()
+	head := make([]byte, 0, sniffSize)
+	for _, line := range m.store.chunks[0].lines {
+		if len(head) >= sniffSize {
+			break
+		}
+		head = append(head, line[:min(len(line), sniffSize-len(head))]...)
+	}
+	m.store.mu.RUnlock()
+
+	if isBinary(head) {
+		atomic.StoreInt32(&m.binary, 1)
+	}
+}
+
+// tailReadable returns true if the tail can be read before counting all lines.
+func (m *Document) tailReadable() bool {
+	return m.seekable && m.CFormat == UNCOMPRESSED && m.file != nil && !m.decoded()
+}
+
 // tmpRead read tail to temporary store.
 // It is executed only once if EOF has not been reached after follow-mode is set.
 func (m *Document) tmpRead(reader *bufio.Reader) (*bufio.Reader, error) {
+	if m.followStore != nil {
+		m.followStore.release()
+	}
 	m.followStore = NewStore()
+	m.followStore.setSeparator(m.store.separator())
 	atomic.StoreInt32(&m.tmpFollow, 1)
 
 	if _, err := m.file.Seek(tailSize*-1, io.SeekEnd); err != nil {
@@ -73,7 +110,7 @@ func (m *Document) continueRead(reader *bufio.Reader) (*bufio.Reader, error) {
 			log.Printf("continueRead: %s", err)
 			m.seekable = false
 		} else {
-			reader.Reset(m.file)
+			reader.Reset(m.seeker)
 		}
 	}
 	chunk := m.store.chunkForAdd(m.seekable, m.store.size)
@@ -97,6 +134,9 @@ func (m *Document) followRead(reader *bufio.Reader) (*bufio.Reader, error) {
 	if !m.FollowMode && !m.FollowAll {
 		return reader, nil
 	}
+	if r := m.checkRotation(); r != notRotated {
+		return m.rotate(reader, r)
+	}
 
 	reader, err := m.loadRead(reader, m.store.lastChunkNum())
 	if err != nil {
@@ -114,7 +154,7 @@ func (m *Document) followRead(reader *bufio.Reader) (*bufio.Reader, error) {
 		if err := m.seekChunk(reader, m.store.offset); err != nil {
 			return nil, fmt.Errorf("followRead: %w", err)
 		}
-		reader = bufio.NewReader(m.file)
+		reader = bufio.NewReader(m.seeker)
 	}
 
 	if err := m.store.readLines(chunk, reader, start, ChunkSize, true); err != nil {
@@ -152,6 +192,15 @@ func (m *Document) reserveChunk(reader *bufio.Reader, start int, end int) error
 
 // loadChunk actually loads the reserved Chunk.
 func (m *Document) loadChunk(reader *bufio.Reader, chunkNum int) (*bufio.Reader, error) {
+	if f := m.rotatedFileOf(chunkNum); f != nil {
+		return reader, m.loadRotatedChunk(f, chunkNum)
+	}
+	if err := m.verifyChunk(chunkNum); err != nil {
+		return reader, err
+	}
+	if m.loadChunkMmap(chunkNum) {
+		return reader, nil
+	}
 	chunk := m.store.chunks[chunkNum]
 	if err := m.seekChunk(reader, chunk.start); err != nil {
 		return nil, err
@@ -170,10 +219,10 @@ func (m *Document) loadChunk(reader *bufio.Reader, chunkNum int) (*bufio.Reader,
 
 // seekChunk seeks to the start of the chunk.
 func (m *Document) seekChunk(reader *bufio.Reader, start int64) error {
-	if _, err := m.file.Seek(start, io.SeekStart); err != nil {
+	if _, err := m.seeker.Seek(start, io.SeekStart); err != nil {
 		return fmt.Errorf("seek: %w", err)
 	}
-	reader.Reset(m.file)
+	reader.Reset(m.seeker)
 	return nil
 }
 
@@ -196,12 +245,22 @@ func (m *Document) loadRead(reader *bufio.Reader, chunkNum int) (*bufio.Reader,
 // loadReadFile loads the read contents into chunks.
 // loadReadFile frees old chunks and loads new chunks.
 func (m *Document) loadReadFile(reader *bufio.Reader, chunkNum int) (*bufio.Reader, error) {
+	if m.store.isPinned(chunkNum) {
+		return reader, nil
+	}
+	if m.store.isStaleMapped(chunkNum) {
+		m.store.unloadChunk(chunkNum)
+	}
 	m.store.swapLoadedFile(chunkNum)
 	if len(m.store.chunks[chunkNum].lines) != 0 {
 		// already loaded.
 		return reader, nil
 	}
-	return m.loadChunk(reader, chunkNum)
+	atomic.StoreInt32(&m.store.loading, int32(chunkNum))
+	reader, err := m.loadChunk(reader, chunkNum)
+	atomic.StoreInt32(&m.store.loading, -1)
+	evictOverBudget(m.store, chunkNum)
+	return reader, err
 }
 
 // loadReadMem loads the read contents into chunks.
@@ -264,6 +323,7 @@ func (m *Document) reloadFile(reader *bufio.Reader) (*bufio.Reader, error) {
 // afterEOF does processing after reaching EOF.
 func (m *Document) afterEOF(reader *bufio.Reader) *bufio.Reader {
 	m.store.offset = m.store.size
+	m.saveIndex()
 	atomic.StoreInt32(&m.store.eof, 1)
 	if atomic.SwapInt32(&m.tmpFollow, 0) == 1 {
 		atomic.StoreInt32(&m.tmpLN, atomic.LoadInt32(&m.followStore.endNum))
@@ -293,24 +353,57 @@ func (m *Document) fileReader(f *os.File) (io.Reader, error) {
 	atomic.StoreInt32(&m.closed, 0)
 	m.file = f
 
+	m.seeker = f
+
 	cFormat := UNCOMPRESSED
 	r := io.Reader(m.file)
 	if !SkipExtract {
-		cFormat, r = uncompressedReader(m.file, m.seekable)
+		if m.seekable {
+			cFormat = fileCompressType(f, m.FileName)
+		}
+		switch {
+		case m.seekable && cFormat == UNCOMPRESSED:
+		case m.seekable && hasSeekPoints(f, cFormat):
+			// Compressed files with seek points are read through the seeker,
+			// so that chunks can be loaded again after being freed.
+			m.seeker = newCompressedSeeker(f, cFormat)
+			r = m.seeker
+		default:
+			// Other compressed files are kept in memory.
+			m.seekable = false
+			cFormat, r = uncompressedReader(m.file, m.FileName, m.seekable)
+		}
 	}
 
-	if cFormat == UNCOMPRESSED {
-		if m.seekable {
-			if _, err := f.Seek(0, io.SeekStart); err != nil {
-				atomic.StoreInt32(&m.closed, 1)
-				return nil, fmt.Errorf("seek: %w", err)
-			}
-			r = f
+	if cFormat == UNCOMPRESSED && m.seekable {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			atomic.StoreInt32(&m.closed, 1)
+			return nil, fmt.Errorf("seek: %w", err)
 		}
-	} else {
-		m.seekable = false
+		r = f
 	}
 	m.CFormat = cFormat
+
+	// Decode the text into UTF-8 after extracting.
+	if m.seekable {
+		enc, ds, err := decodedSeeker(m.seeker, m.encodingName())
+		if err != nil {
+			atomic.StoreInt32(&m.closed, 1)
+			return nil, fmt.Errorf("encoding: %w", err)
+		}
+		if ds != nil {
+			m.seeker = ds
+			r = ds
+		}
+		m.Encoding = enc
+		// Only the file that is read as it is can be mapped.
+		if Mmap && cFormat == UNCOMPRESSED && ds == nil {
+			m.store.mmap = newMmapFile(f)
+		}
+	} else {
+		m.Encoding, r = decodedReader(r, m.encodingName())
+	}
+
 	if STDOUTPIPE != nil {
 		r = io.TeeReader(r, STDOUTPIPE)
 	}
@@ -363,8 +456,12 @@ func (m *Document) reset() {
 	if !m.BufEOF() {
 		return
 	}
+	sep, crlf := m.store.separator()
+	m.store.release()
 	m.store = NewStore()
+	m.store.setSeparator(sep, crlf)
 	m.store.setNewLoadChunks(m.memoryLimit)
+	m.closeRotatedFiles()
 	atomic.StoreInt32(&m.store.changed, 1)
 	m.ClearCache()
 }
@@ -381,9 +478,12 @@ func (m *Document) close() error {
 		return nil
 	}
 
-	if err := m.file.Close(); err != nil {
-		return fmt.Errorf("close: %w", err)
+	if m.file != nil {
+		if err := m.file.Close(); err != nil {
+			return fmt.Errorf("close: %w", err)
+		}
 	}
+	m.closeRotatedFiles()
 	atomic.StoreInt32(&m.store.eof, 1)
 	atomic.StoreInt32(&m.closed, 1)
 	atomic.StoreInt32(&m.store.changed, 1)
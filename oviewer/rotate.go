@@ -0,0 +1,205 @@
+package oviewer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// rotation represents how the followed file has been rotated.
+type rotation int
+
+const (
+	// notRotated is the file that has not been rotated.
+	notRotated rotation = iota
+	// rotateTruncated is the file truncated in place (copytruncate).
+	rotateTruncated
+	// rotateReplaced is the file replaced by another file with the same name.
+	rotateReplaced
+)
+
+// String returns the description of the rotation.
+func (r rotation) String() string {
+	switch r {
+	case rotateTruncated:
+		return "file truncated"
+	case rotateReplaced:
+		return "file replaced"
+	}
+	return "not rotated"
+}
+
+// rotatedFile is the file replaced while following.
+// It is kept open, so that the chunks can be read again after being freed.
+type rotatedFile struct {
+	file *os.File
+	// chunks is the number of chunks read from the file.
+	chunks int
+}
+
+// checkRotation returns how the file has been rotated since it was read.
+// Truncation is detected by the size being smaller than the size read,
+// and replacement is detected by the file name pointing to another file (inode/device) in FollowName.
+func (m *Document) checkRotation() rotation {
+	if m.file == nil || m.FileName == "" || m.CFormat != UNCOMPRESSED || m.decoded() {
+		return notRotated
+	}
+	if m.Encoding != "" && m.Encoding != encodingUTF8 {
+		return notRotated
+	}
+	fi, err := m.file.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		return notRotated
+	}
+	if m.FollowName {
+		if nfi, err := os.Stat(m.FileName); err == nil && !os.SameFile(fi, nfi) {
+			return rotateReplaced
+		}
+	}
+	m.store.mu.RLock()
+	read := m.store.size
+	m.store.mu.RUnlock()
+	if fi.Size() < read {
+		return rotateTruncated
+	}
+	return notRotated
+}
+
+// rotate follows the rotated file.
+// The document is read again from the beginning of the file,
+// or the new content is appended after a rotation marker if KeepRotated is set.
+func (m *Document) rotate(reader *bufio.Reader, r rotation) (*bufio.Reader, error) {
+	log.Printf("%s: %s", m.FileName, r)
+	if !m.KeepRotated {
+		m.store.loadedChunks.Purge()
+		m.reset()
+		reader, err := m.reloadFile(reader)
+		m.requestStart()
+		return reader, err
+	}
+
+	lastChunk := m.keepChunks(reader, r)
+	atomic.StoreInt32(&m.store.noNewlineEOF, 0)
+	chunk := m.store.chunkForAdd(m.seekable, m.store.size)
+	sep, _ := m.store.separator()
+	m.store.appendLine(chunk, rotationMarker(m.FileName, r, sep))
+	// The chunks of the rotated file and the marker are kept in memory.
+	// The truncated file cannot be read again, so all chunks are kept.
+	first := lastChunk
+	if r == rotateTruncated {
+		first = 1
+	}
+	m.store.pin(first, m.store.lastChunkNum())
+	m.ClearCache()
+
+	if r == rotateReplaced {
+		m.rotatedFiles = append(m.rotatedFiles, rotatedFile{file: m.file, chunks: lastChunk})
+		f, err := open(m.FileName)
+		if err != nil {
+			return nil, err
+		}
+		rd, err := m.fileReader(f)
+		if err != nil {
+			return nil, err
+		}
+		reader = bufio.NewReader(rd)
+	} else {
+		if _, err := m.file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("rotate: %w", err)
+		}
+		reader = bufio.NewReader(m.file)
+	}
+	// The chunks after the marker start from the beginning of the new file.
+	m.store.mu.Lock()
+	m.store.size = 0
+	m.store.offset = 0
+	m.store.mu.Unlock()
+	// Read the new content.
+	return m.followRead(reader)
+}
+
+// keepChunks loads the chunks that are kept in memory after the rotation
+// and returns the last chunk number of the rotated file.
+// The replaced file is kept open, so only the last chunk is loaded.
+// All chunks of the truncated file are loaded,
+// and lines that can no longer be read are kept as empty lines.
+func (m *Document) keepChunks(reader *bufio.Reader, r rotation) int {
+	s := m.store
+	s.mu.Lock()
+	for _, chunk := range s.chunks {
+		s.unmapChunk(chunk)
+	}
+	s.mmap = nil
+	s.mu.Unlock()
+
+	lastChunk := s.lastChunkNum()
+	first := lastChunk
+	if r == rotateTruncated {
+		first = 1
+	}
+	lost := 0
+	for chunkNum := first; chunkNum <= lastChunk; chunkNum++ {
+		start, end := s.chunkRange(chunkNum)
+		chunk := s.chunks[chunkNum]
+		if len(chunk.lines) == 0 && !s.isPinned(chunkNum) {
+			if _, err := m.loadChunk(reader, chunkNum); err != nil {
+				log.Printf("keep chunk %d: %s", chunkNum, err)
+			}
+		}
+		s.mu.Lock()
+		for n := len(chunk.lines); n < end-start; n++ {
+			chunk.lines = append(chunk.lines, nil)
+			lost++
+		}
+		s.mu.Unlock()
+	}
+	if lost > 0 {
+		log.Printf("%s: %d lines were lost by rotation", m.FileName, lost)
+	}
+	return lastChunk
+}
+
+// rotatedFileOf returns the rotated file that contains the chunk.
+// It returns nil if the chunk is in the current file.
+func (m *Document) rotatedFileOf(chunkNum int) *os.File {
+	for _, rf := range m.rotatedFiles {
+		if chunkNum < rf.chunks {
+			return rf.file
+		}
+	}
+	return nil
+}
+
+// loadRotatedChunk loads the chunk from the rotated file.
+func (m *Document) loadRotatedChunk(f *os.File, chunkNum int) error {
+	chunk := m.store.chunks[chunkNum]
+	reader := bufio.NewReader(io.NewSectionReader(f, chunk.start, math.MaxInt64-chunk.start))
+	start, end := m.store.chunkRange(chunkNum)
+	if err := m.store.readLines(chunk, reader, start, end, false); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("rotated chunk %d: %w", chunkNum, err)
+	}
+	return nil
+}
+
+// closeRotatedFiles closes the rotated files.
+func (m *Document) closeRotatedFiles() {
+	for _, rf := range m.rotatedFiles {
+		if err := rf.file.Close(); err != nil {
+			log.Printf("close rotated file: %s", err)
+		}
+	}
+	m.rotatedFiles = nil
+}
+
+// rotationMarker returns the line that marks the rotation.
+func rotationMarker(fileName string, r rotation, sep byte) []byte {
+	marker := fmt.Sprintf("\x1b[7m==> %s: %s at %s <==\x1b[0m", filepath.Base(fileName), r, time.Now().Format(time.RFC3339))
+	return append([]byte(marker), sep)
+}
@@ -0,0 +1,221 @@
+package oviewer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rotateTestLines(prefix string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%s %d\n", prefix, i)
+	}
+	return b.String()
+}
+
+// rotateTestFollow requests follow and waits until the document has n lines.
+func rotateTestFollow(t *testing.T, m *Document, n int) {
+	t.Helper()
+	sc := controlSpecifier{
+		request: requestFollow,
+		done:    make(chan bool),
+	}
+	m.ctlCh <- sc
+	<-sc.done
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.BufEOF() || m.BufEndNum() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("BufEndNum() = %d, want %d", m.BufEndNum(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDocument_checkRotation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		followName bool
+		rotate     func(t *testing.T, fileName string)
+		want       rotation
+	}{
+		{
+			name:   "appended",
+			rotate: func(t *testing.T, fileName string) { appendTestFile(t, fileName, "new\n") },
+			want:   notRotated,
+		},
+		{
+			name: "truncated",
+			rotate: func(t *testing.T, fileName string) {
+				if err := os.Truncate(fileName, 0); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: rotateTruncated,
+		},
+		{
+			name:       "replaced",
+			followName: true,
+			rotate:     replaceTestFile,
+			want:       rotateReplaced,
+		},
+		{
+			name:   "replaced without follow name",
+			rotate: replaceTestFile,
+			want:   notRotated,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fileName := filepath.Join(t.TempDir(), "test.log")
+			if err := os.WriteFile(fileName, []byte(rotateTestLines("old", 10)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m := openIndexTestDocument(t, fileName)
+			m.FollowName = tt.followName
+			tt.rotate(t, fileName)
+			if got := m.checkRotation(); got != tt.want {
+				t.Errorf("checkRotation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func appendTestFile(t *testing.T, fileName string, str string) {
+	t.Helper()
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(str); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func replaceTestFile(t *testing.T, fileName string) {
+	t.Helper()
+	if err := os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, []byte("new 0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDocument_rotate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		keepRotated bool
+		followName  bool
+		rotate      func(t *testing.T, fileName string)
+		want        []string
+	}{
+		{
+			name: "truncated",
+			rotate: func(t *testing.T, fileName string) {
+				if err := os.WriteFile(fileName, []byte("new 0\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"new 0"},
+		},
+		{
+			name:        "truncated keep",
+			keepRotated: true,
+			rotate: func(t *testing.T, fileName string) {
+				if err := os.WriteFile(fileName, []byte("new 0\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"old 0", "old 1", "old 2", "==> test.log: file truncated", "new 0"},
+		},
+		{
+			name:        "replaced keep",
+			keepRotated: true,
+			followName:  true,
+			rotate:      replaceTestFile,
+			want:        []string{"old 0", "old 1", "old 2", "==> test.log: file replaced", "new 0"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fileName := filepath.Join(t.TempDir(), "test.log")
+			if err := os.WriteFile(fileName, []byte(rotateTestLines("old", 3)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m := openIndexTestDocument(t, fileName)
+			m.FollowMode = true
+			m.FollowName = tt.followName
+			m.KeepRotated = tt.keepRotated
+			tt.rotate(t, fileName)
+			rotateTestFollow(t, m, len(tt.want))
+			for lN, want := range tt.want {
+				if got := m.LineString(lN); !strings.Contains(got, want) {
+					t.Errorf("line %d = %q, want %q", lN, got, want)
+				}
+			}
+
+			// Appended to the new file.
+			appendTestFile(t, fileName, "new 1\n")
+			rotateTestFollow(t, m, len(tt.want)+1)
+			if got := m.LineString(len(tt.want)); got != "new 1" {
+				t.Errorf("line %d = %q, want %q", len(tt.want), got, "new 1")
+			}
+		})
+	}
+}
+
+func TestDocument_rotateKeepReplaced(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(fileName, []byte(rotateTestLines("old", 25000)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	m.FollowMode = true
+	m.FollowName = true
+	m.KeepRotated = true
+	replaceTestFile(t, fileName)
+	rotateTestFollow(t, m, 25002)
+	appendTestFile(t, fileName, rotateTestLines("more", 20000))
+	rotateTestFollow(t, m, 45002)
+
+	if !m.seekable {
+		t.Fatal("seekable = false, want true")
+	}
+	if len(m.rotatedFiles) != 1 {
+		t.Fatalf("rotatedFiles = %d, want 1", len(m.rotatedFiles))
+	}
+	// The chunk of the marker is kept in memory.
+	if !m.store.isPinned(2) {
+		t.Errorf("chunk 2 is not pinned")
+	}
+	// The freed chunks are read again from the rotated file and the new file.
+	m.store.unloadChunk(1)
+	m.store.unloadChunk(3)
+	tests := []struct {
+		lN   int
+		want string
+	}{
+		{lN: 12345, want: "old 12345"},
+		{lN: 24999, want: "old 24999"},
+		{lN: 25000, want: "==> test.log: file replaced"},
+		{lN: 25001, want: "new 0"},
+		{lN: 35000, want: "more 9998"},
+	}
+	for _, tt := range tests {
+		if got := indexTestLine(t, m, tt.lN); !strings.Contains(got, tt.want) {
+			t.Errorf("line %d = %q, want %q", tt.lN, got, tt.want)
+		}
+	}
+}
@@ -230,9 +230,15 @@ func (root *Root) searchPosition(lN int, str string) [][]int {
 }
 
 // searchXPos returns the x position of the first match.
+// The rest of a long line is converted until it matches.
 func (root *Root) searchXPos(lN int) int {
-	line, _ := root.Doc.getLineC(lN, root.Doc.TabWidth)
+	m := root.Doc
+	line, _ := m.getLineC(lN, m.TabWidth)
 	indexes := root.searchPosition(lN, line.str)
+	for len(indexes) == 0 && line.lazy != nil {
+		line, _ = m.getLineCWidth(lN, m.TabWidth, max(len(line.lc)*2, longLineSize))
+		indexes = root.searchPosition(lN, line.str)
+	}
 	if len(indexes) == 0 {
 		return 0
 	}
@@ -291,6 +297,12 @@ func (root *Root) searchMove(ctx context.Context, forward bool, lN int, searcher
 		return
 	}
 	word := root.searcher.String()
+	if root.Doc.hex != nil {
+		if pattern, ok := hexPattern(word); ok {
+			root.hexSearchMove(ctx, forward, lN, pattern)
+			return
+		}
+	}
 	root.setMessagef("search:%v (%v)Cancel", word, strings.Join(root.cancelKeys, ","))
 	eg, ctx := errgroup.WithContext(ctx)
 	ctx, cancel := context.WithCancel(ctx)
@@ -667,18 +679,23 @@ func (root *Root) sendBackSearch(str string) {
 func (m *Document) searchChunk(chunkNum int, searcher Searcher) (int, error) {
 	// Seek to the start of the chunk.
 	chunk := m.store.chunks[chunkNum]
-	if _, err := m.file.Seek(chunk.start, io.SeekStart); err != nil {
+	seeker := m.seeker
+	if f := m.rotatedFileOf(chunkNum); f != nil {
+		seeker = f
+	}
+	if _, err := seeker.Seek(chunk.start, io.SeekStart); err != nil {
 		return 0, fmt.Errorf("seek: %w", err)
 	}
 
 	// Read the chunk line by line.
-	reader := bufio.NewReader(m.file)
+	reader := bufio.NewReader(seeker)
+	sep, crlf := m.store.separator()
 	var line bytes.Buffer
 	var isPrefix bool
 	num := 0
 	for num < ChunkSize {
 		// Read a line.
-		buf, err := reader.ReadSlice('\n')
+		buf, err := reader.ReadSlice(sep)
 		if errors.Is(err, bufio.ErrBufferFull) {
 			isPrefix = true
 			err = nil
@@ -687,7 +704,7 @@ func (m *Document) searchChunk(chunkNum int, searcher Searcher) (int, error) {
 
 		// If the line is complete, check if it matches.
 		if !isPrefix {
-			if searcher.Match(bytes.TrimSuffix(line.Bytes(), []byte("\n"))) {
+			if searcher.Match(trimSeparator(line.Bytes(), sep, crlf)) {
 				return num, nil
 			}
 			num++
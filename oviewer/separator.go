@@ -0,0 +1,111 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// ParseRecordSeparator returns the byte that separates records (lines)
+// and whether CR before LF is removed.
+// The separator is lf, crlf, cr, nul, or a single character
+// that can be written with an escape sequence (e.g. \x1e).
+func ParseRecordSeparator(str string) (byte, bool, error) {
+	switch strings.ToLower(str) {
+	case "", "lf", `\n`, "\n":
+		return '\n', false, nil
+	case "crlf", `\r\n`, "\r\n":
+		return '\n', true, nil
+	case "cr", `\r`, "\r":
+		return '\r', false, nil
+	case "nul", "null", `\0`, "\x00":
+		return 0, false, nil
+	}
+	if s, err := strconv.Unquote(`"` + str + `"`); err == nil && len(s) == 1 {
+		return s[0], false, nil
+	}
+	return '\n', false, fmt.Errorf("%w: %s", ErrInvalidSeparator, str)
+}
+
+// separatorName returns the name of the record separator.
+func separatorName(sep byte, crlf bool) string {
+	switch {
+	case sep == '\n' && crlf:
+		return "crlf"
+	case sep == '\n':
+		return "lf"
+	case sep == '\r':
+		return "cr"
+	case sep == 0:
+		return "nul"
+	case sep < 0x20 || sep >= 0x7f:
+		return fmt.Sprintf(`\x%02x`, sep)
+	}
+	return string(sep)
+}
+
+// setSeparator sets the record separator of the store.
+func (s *store) setSeparator(sep byte, crlf bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sep = sep
+	s.crlf = crlf
+}
+
+// separator returns the record separator of the store.
+func (s *store) separator() (byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sep, s.crlf
+}
+
+// trimSeparator returns the line without the record separator.
+// If crlf is true, CR before LF is also removed.
+func trimSeparator(line []byte, sep byte, crlf bool) []byte {
+	if len(line) == 0 || line[len(line)-1] != sep {
+		return line
+	}
+	line = line[:len(line)-1]
+	if crlf {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	return line
+}
+
+// setDefaultSeparator sets the record separator specified by the option.
+func (m *Document) setDefaultSeparator() {
+	sep, crlf, err := ParseRecordSeparator(RecordSeparator)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	m.store.setSeparator(sep, crlf)
+}
+
+// setRecordSeparator sets the record separator of the current document and reloads it.
+func (root *Root) setRecordSeparator(input string) {
+	sep, crlf, err := ParseRecordSeparator(input)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	m := root.Doc
+	if s, c := m.store.separator(); s == sep && c == crlf {
+		return
+	}
+	// CRLF only changes the display, so it does not need to be read again.
+	if s, _ := m.store.separator(); s == sep {
+		m.store.setSeparator(sep, crlf)
+		m.ClearCache()
+		root.setMessagef("Set record separator %s", separatorName(sep, crlf))
+		return
+	}
+	m.store.setSeparator(sep, crlf)
+	if err := m.reload(); err != nil {
+		root.setMessagef("record separator: %s", err)
+		return
+	}
+	root.setMessagef("Set record separator %s", separatorName(sep, crlf))
+}
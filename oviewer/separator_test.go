@@ -0,0 +1,169 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRecordSeparator(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		str      string
+		wantSep  byte
+		wantCRLF bool
+		wantErr  bool
+	}{
+		{name: "default", str: "", wantSep: '\n'},
+		{name: "lf", str: "lf", wantSep: '\n'},
+		{name: "crlf", str: "CRLF", wantSep: '\n', wantCRLF: true},
+		{name: "cr", str: `\r`, wantSep: '\r'},
+		{name: "nul", str: "nul", wantSep: 0},
+		{name: "escaped nul", str: `\0`, wantSep: 0},
+		{name: "character", str: ";", wantSep: ';'},
+		{name: "hex", str: `\x1e`, wantSep: 0x1e},
+		{name: "too long", str: "ab", wantSep: '\n', wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sep, crlf, err := ParseRecordSeparator(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRecordSeparator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sep != tt.wantSep || crlf != tt.wantCRLF {
+				t.Errorf("ParseRecordSeparator() = %q, %v, want %q, %v", sep, crlf, tt.wantSep, tt.wantCRLF)
+			}
+		})
+	}
+}
+
+func Test_trimSeparator(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		line string
+		sep  byte
+		crlf bool
+		want string
+	}{
+		{name: "lf", line: "abc\n", sep: '\n', want: "abc"},
+		{name: "lf keeps cr", line: "abc\r\n", sep: '\n', want: "abc\r"},
+		{name: "crlf", line: "abc\r\n", sep: '\n', crlf: true, want: "abc"},
+		{name: "no separator", line: "abc", sep: '\n', crlf: true, want: "abc"},
+		{name: "nul", line: "a\nb\x00", sep: 0, want: "a\nb"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := string(trimSeparator([]byte(tt.line), tt.sep, tt.crlf)); got != tt.want {
+				t.Errorf("trimSeparator() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_recordSeparator(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		str   string
+		sep   byte
+		crlf  bool
+		want  []string
+		wantW string
+	}{
+		{
+			name:  "nul",
+			str:   "a b\x00c\nd\x00e",
+			sep:   0,
+			want:  []string{"a b", "c\nd", "e"},
+			wantW: "a b\x00c\nd\x00e",
+		},
+		{
+			name:  "cr",
+			str:   "a\rb\rc\r",
+			sep:   '\r',
+			want:  []string{"a", "b", "c"},
+			wantW: "a\rb\rc\r",
+		},
+		{
+			name:  "crlf",
+			str:   "a,1\r\nb,2\r\n",
+			sep:   '\n',
+			crlf:  true,
+			want:  []string{"a,1", "b,2"},
+			wantW: "a,1\r\nb,2\r\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.store.setSeparator(tt.sep, tt.crlf)
+			if err := m.ReadAll(bytes.NewBufferString(tt.str)); err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+			}
+			if got := m.BufEndNum(); got != len(tt.want) {
+				t.Fatalf("BufEndNum() = %d, want %d", got, len(tt.want))
+			}
+			for lN, want := range tt.want {
+				if got := m.LineString(lN); got != want {
+					t.Errorf("LineString(%d) = %q, want %q", lN, got, want)
+				}
+			}
+			w := &bytes.Buffer{}
+			if err := m.Export(w, 0, m.BufEndNum()); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.String(); got != tt.wantW {
+				t.Errorf("Export() = %q, want %q", got, tt.wantW)
+			}
+		})
+	}
+}
+
+func TestDocument_recordSeparatorFile(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "print0.txt")
+	var b strings.Builder
+	for i := 0; i < 25000; i++ {
+		fmt.Fprintf(&b, "./dir/file %d\x00", i)
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	if got := m.BufEndNum(); got != 1 {
+		t.Fatalf("BufEndNum() = %d, want 1", got)
+	}
+
+	// Change the separator of the document and read it again.
+	m.store.setSeparator(0, false)
+	if err := m.reload(); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	if got := m.BufEndNum(); got != 25000 {
+		t.Fatalf("BufEndNum() = %d, want 25000", got)
+	}
+	for _, lN := range []int{0, 24999, 10000, 19999, 12345} {
+		want := fmt.Sprintf("./dir/file %d", lN)
+		if got := indexTestLine(t, m, lN); got != want {
+			t.Errorf("line %d = %q, want %q", lN, got, want)
+		}
+	}
+}
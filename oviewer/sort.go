@@ -0,0 +1,286 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// sortType represents the type of comparison when sorting.
+type sortType int
+
+const (
+	// sortLexical compares as strings.
+	sortLexical sortType = iota
+	// sortNumeric compares as numbers.
+	sortNumeric
+	// sortSize compares as human readable sizes(1K, 2.5M, 3G...).
+	sortSize
+	// sortTime compares as date and time.
+	sortTime
+)
+
+// String returns the string representation of the sort type.
+func (t sortType) String() string {
+	switch t {
+	case sortNumeric:
+		return "numeric"
+	case sortSize:
+		return "size"
+	case sortTime:
+		return "time"
+	}
+	return "lexical"
+}
+
+// parseSortOption returns the sort type and the order from a string.
+// The string is "type [asc|desc]".
+// e.g. "numeric", "size desc", "time asc".
+func parseSortOption(str string) (sortType, bool, error) {
+	fields := strings.Fields(strings.ToLower(str))
+	if len(fields) == 0 {
+		return sortLexical, false, nil
+	}
+
+	var sType sortType
+	switch fields[0] {
+	case "lexical", "l", "string", "s":
+		sType = sortLexical
+	case "numeric", "n", "number":
+		sType = sortNumeric
+	case "size", "h", "human":
+		sType = sortSize
+	case "time", "t", "date":
+		sType = sortTime
+	default:
+		return sortLexical, false, fmt.Errorf("%w: %s", ErrInvalidSortType, fields[0])
+	}
+
+	desc := false
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "asc", "a":
+			desc = false
+		case "desc", "d", "r", "reverse":
+			desc = true
+		default:
+			return sType, false, fmt.Errorf("%w: %s", ErrInvalidSortType, fields[1])
+		}
+	}
+	return sType, desc, nil
+}
+
+// sortKey is the value to compare when sorting.
+type sortKey struct {
+	str   string
+	num   float64
+	valid bool
+}
+
+// newSortKey converts a column string into a sortKey.
+func newSortKey(str string, sType sortType) sortKey {
+	key := sortKey{str: str}
+	switch sType {
+	case sortLexical:
+		key.valid = true
+	case sortNumeric:
+		num, err := strconv.ParseFloat(strings.ReplaceAll(str, ",", ""), 64)
+		key.num, key.valid = num, err == nil
+	case sortSize:
+		key.num, key.valid = parseHumanSize(str)
+	case sortTime:
+		t, ok := parseTime(str)
+		key.num, key.valid = float64(t.UnixNano()), ok
+	}
+	return key
+}
+
+// lessSortKey returns whether a should be sorted before b.
+// Invalid values are always placed after valid values.
+func lessSortKey(a sortKey, b sortKey, sType sortType, desc bool) bool {
+	if a.valid != b.valid {
+		return a.valid
+	}
+	if !a.valid || sType == sortLexical {
+		if desc {
+			return a.str > b.str
+		}
+		return a.str < b.str
+	}
+	if desc {
+		return a.num > b.num
+	}
+	return a.num < b.num
+}
+
+// humanSizeReg is a regular expression that matches human readable sizes.
+var humanSizeReg = regexp.MustCompile(`^(?i)([+-]?[0-9]*\.?[0-9]+)\s*([KMGTPE]?)(i?B)?$`)
+
+// parseHumanSize parses human readable sizes (1K, 2.5M, 3GiB...) and returns bytes.
+func parseHumanSize(str string) (float64, bool) {
+	match := humanSizeReg.FindStringSubmatch(strings.TrimSpace(str))
+	if match == nil {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := strings.ToUpper(match[2])
+	if unit == "" {
+		return num, true
+	}
+	return num * float64(uint64(1)<<(10*(strings.Index("KMGTPE", unit)+1))), true
+}
+
+// timeLayouts is the layout of the date and time to parse.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+	time.StampNano,
+	time.Stamp,
+	"15:04:05.999999999",
+	"15:04:05",
+	"15:04",
+}
+
+// parseTime parses the string with timeLayouts.
+func parseTime(str string) (time.Time, bool) {
+	str = strings.TrimSpace(str)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sortLine is one line to sort.
+type sortLine struct {
+	key  sortKey
+	line []byte
+	lN   int
+}
+
+// sortDocument returns a new document whose body is sorted by the specified column.
+// The header lines(SkipLines and Header) stay in place.
+// The new document keeps the line numbers of the original document.
+func (m *Document) sortDocument(ctx context.Context, cursor int, sType sortType, desc bool) (*Document, error) {
+	firstLine := m.firstLine()
+	heads := make([]sortLine, 0, firstLine)
+	lines := make([]sortLine, 0, m.BufEndNum())
+	err := m.rangeLines(ctx, 0, m.BufEndNum(), func(lN int, line []byte) error {
+		buf := make([]byte, len(line))
+		copy(buf, line)
+		if lN < firstLine {
+			heads = append(heads, sortLine{line: buf, lN: lN})
+			return nil
+		}
+		str, err := m.bytesColumnString(buf, cursor)
+		key := newSortKey(str, sType)
+		if err != nil {
+			key.valid = false
+		}
+		lines = append(lines, sortLine{key: key, line: buf, lN: lN})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lessSortKey(lines[i].key, lines[j].key, sType, desc)
+	})
+
+	var buf bytes.Buffer
+	lineNumMap := make([]int, 0, len(heads)+len(lines))
+	for _, l := range append(heads, lines...) {
+		buf.Write(l.line)
+		buf.WriteByte('\n')
+		lineNumMap = append(lineNumMap, l.lN)
+	}
+
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	doc.FileName = m.FileName
+	doc.Caption = fmt.Sprintf("(sort:%d %s %s)%s", cursor, sType, order, m.FileName)
+	doc.parent = m
+	doc.lineNumMap = lineNumMap
+	doc.general = m.general
+	doc.preventReload = true
+	if err := doc.ControlReader(&buf, nil); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// sortColumn sorts the current document by the column of the cursor
+// and adds the result as a new document.
+func (root *Root) sortColumn(ctx context.Context, input string) {
+	m := root.Doc
+	if !m.ColumnMode {
+		root.setMessage("sort: column mode is not enabled")
+		return
+	}
+	sType, desc, err := parseSortOption(input)
+	if err != nil {
+		root.setMessagef("sort: %s", err)
+		return
+	}
+
+	cursor := m.columnCursor
+	root.setMessagef("sort:column %d %s (%v)Cancel", cursor, sType, strings.Join(root.cancelKeys, ","))
+	eg, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eg.Go(func() error {
+		return root.cancelWait(cancel)
+	})
+
+	var doc *Document
+	eg.Go(func() error {
+		defer root.sendSearchQuit()
+		var err error
+		doc, err = m.sortDocument(ctx, cursor, sType, desc)
+		return err
+	})
+
+	if err := eg.Wait(); err != nil {
+		root.setMessageLogf("sort: %s", err)
+		return
+	}
+	root.addDocument(doc)
+	root.setMessagef("sorted by column %d (%s)", cursor, sType)
+}
@@ -0,0 +1,230 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_parseSortOption(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		str      string
+		want     sortType
+		wantDesc bool
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			str:      "",
+			want:     sortLexical,
+			wantDesc: false,
+			wantErr:  false,
+		},
+		{
+			name:     "numeric",
+			str:      "numeric",
+			want:     sortNumeric,
+			wantDesc: false,
+			wantErr:  false,
+		},
+		{
+			name:     "sizeDesc",
+			str:      "size desc",
+			want:     sortSize,
+			wantDesc: true,
+			wantErr:  false,
+		},
+		{
+			name:     "timeAsc",
+			str:      "Time ASC",
+			want:     sortTime,
+			wantDesc: false,
+			wantErr:  false,
+		},
+		{
+			name:    "invalid",
+			str:     "invalid",
+			want:    sortLexical,
+			wantErr: true,
+		},
+		{
+			name:    "invalidOrder",
+			str:     "numeric up",
+			want:    sortNumeric,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, gotDesc, err := parseSortOption(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSortOption() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseSortOption() got = %v, want %v", got, tt.want)
+			}
+			if gotDesc != tt.wantDesc {
+				t.Errorf("parseSortOption() gotDesc = %v, want %v", gotDesc, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func Test_parseHumanSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		str    string
+		want   float64
+		wantOK bool
+	}{
+		{
+			name:   "bytes",
+			str:    "512",
+			want:   512,
+			wantOK: true,
+		},
+		{
+			name:   "kilo",
+			str:    "1K",
+			want:   1024,
+			wantOK: true,
+		},
+		{
+			name:   "mega",
+			str:    "1.5M",
+			want:   1.5 * 1024 * 1024,
+			wantOK: true,
+		},
+		{
+			name:   "gib",
+			str:    "2 GiB",
+			want:   2 * 1024 * 1024 * 1024,
+			wantOK: true,
+		},
+		{
+			name:   "invalid",
+			str:    "abc",
+			want:   0,
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, gotOK := parseHumanSize(tt.str)
+			if got != tt.want {
+				t.Errorf("parseHumanSize() got = %v, want %v", got, tt.want)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("parseHumanSize() gotOK = %v, want %v", gotOK, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDocument_sortDocument(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		cursor int
+		sType  sortType
+		desc   bool
+	}
+	tests := []struct {
+		name        string
+		str         string
+		header      int
+		args        args
+		want        []string
+		wantLineNum []int
+	}{
+		{
+			name:   "lexical",
+			str:    "b,2\nc,10\na,1\n",
+			header: 0,
+			args: args{
+				cursor: 0,
+				sType:  sortLexical,
+				desc:   false,
+			},
+			want:        []string{"a,1", "b,2", "c,10"},
+			wantLineNum: []int{2, 0, 1},
+		},
+		{
+			name:   "numericHeader",
+			str:    "name,num\nb,2\nc,10\na,1\n",
+			header: 1,
+			args: args{
+				cursor: 1,
+				sType:  sortNumeric,
+				desc:   false,
+			},
+			want:        []string{"name,num", "a,1", "b,2", "c,10"},
+			wantLineNum: []int{0, 3, 1, 2},
+		},
+		{
+			name:   "sizeDesc",
+			str:    "a,1K\nb,2M\nc,-\nd,3\n",
+			header: 0,
+			args: args{
+				cursor: 1,
+				sType:  sortSize,
+				desc:   true,
+			},
+			want:        []string{"b,2M", "a,1K", "d,3", "c,-"},
+			wantLineNum: []int{1, 0, 3, 2},
+		},
+		{
+			name:   "time",
+			str:    "a,2023-01-02 10:00:00\nb,2022-12-31 23:59:59\nc,2023-01-01 00:00:00\n",
+			header: 0,
+			args: args{
+				cursor: 1,
+				sType:  sortTime,
+				desc:   false,
+			},
+			want:        []string{"b,2022-12-31 23:59:59", "c,2023-01-01 00:00:00", "a,2023-01-02 10:00:00"},
+			wantLineNum: []int{1, 2, 0},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ColumnDelimiter = ","
+			m.Header = tt.header
+			if err := m.ControlReader(bytes.NewBufferString(tt.str), nil); err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+			}
+			doc, err := m.sortDocument(context.Background(), tt.args.cursor, tt.args.sType, tt.args.desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for !doc.BufEOF() {
+			}
+			got := make([]string, 0, doc.BufEndNum())
+			for n := 0; n < doc.BufEndNum(); n++ {
+				got = append(got, doc.LineString(n))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.sortDocument() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(doc.lineNumMap, tt.wantLineNum) {
+				t.Errorf("Document.sortDocument() lineNumMap = %v, want %v", doc.lineNumMap, tt.wantLineNum)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+// statsTopN is the number of most frequent values displayed in the column statistics.
+const statsTopN = 10
+
+// columnStat represents the statistics of a column.
+type columnStat struct {
+	// counts is the number of occurrences for each value.
+	counts map[string]int
+	// lexMin is the minimum value compared as a string.
+	lexMin string
+	// lexMax is the maximum value compared as a string.
+	lexMax string
+	// count is the number of values.
+	count int
+	// empty is the number of empty values.
+	empty int
+	// numCount is the number of numeric values.
+	numCount int
+	// min is the minimum numeric value.
+	min float64
+	// max is the maximum numeric value.
+	max float64
+	// sum is the sum of numeric values.
+	sum float64
+	// cursor is the column number.
+	cursor int
+}
+
+// statValue is a value and its number of occurrences.
+type statValue struct {
+	value string
+	count int
+}
+
+// newColumnStat returns a new columnStat.
+func newColumnStat(cursor int) *columnStat {
+	return &columnStat{
+		cursor: cursor,
+		counts: make(map[string]int),
+	}
+}
+
+// add adds a value to the statistics.
+func (s *columnStat) add(str string) {
+	s.count++
+	if str == "" {
+		s.empty++
+		return
+	}
+	if len(s.counts) == 0 || str < s.lexMin {
+		s.lexMin = str
+	}
+	if len(s.counts) == 0 || str > s.lexMax {
+		s.lexMax = str
+	}
+	s.counts[str]++
+
+	num, err := strconv.ParseFloat(strings.ReplaceAll(str, ",", ""), 64)
+	if err != nil {
+		return
+	}
+	if s.numCount == 0 || num < s.min {
+		s.min = num
+	}
+	if s.numCount == 0 || num > s.max {
+		s.max = num
+	}
+	s.sum += num
+	s.numCount++
+}
+
+// mean returns the mean of numeric values.
+func (s *columnStat) mean() float64 {
+	if s.numCount == 0 {
+		return 0
+	}
+	return s.sum / float64(s.numCount)
+}
+
+// top returns the n most frequent values.
+// Values with the same count are sorted as strings.
+func (s *columnStat) top(n int) []statValue {
+	values := make([]statValue, 0, len(s.counts))
+	for v, c := range s.counts {
+		values = append(values, statValue{value: v, count: c})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].count != values[j].count {
+			return values[i].count > values[j].count
+		}
+		return values[i].value < values[j].value
+	})
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+// String returns the statistics as a report.
+func (s *columnStat) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "column\t%d\n", s.cursor)
+	fmt.Fprintf(&b, "count\t%d\n", s.count)
+	fmt.Fprintf(&b, "empty\t%d\n", s.empty)
+	fmt.Fprintf(&b, "distinct\t%d\n", len(s.counts))
+	if s.numCount > 0 && s.numCount == s.count-s.empty {
+		fmt.Fprintf(&b, "min\t%s\n", formatStatNum(s.min))
+		fmt.Fprintf(&b, "max\t%s\n", formatStatNum(s.max))
+	} else {
+		fmt.Fprintf(&b, "min\t%s\n", s.lexMin)
+		fmt.Fprintf(&b, "max\t%s\n", s.lexMax)
+	}
+	fmt.Fprintf(&b, "numeric\t%d\n", s.numCount)
+	if s.numCount > 0 {
+		fmt.Fprintf(&b, "sum\t%s\n", formatStatNum(s.sum))
+		fmt.Fprintf(&b, "mean\t%s\n", formatStatNum(s.mean()))
+	}
+	fmt.Fprintf(&b, "\ntop %d\n", statsTopN)
+	for _, v := range s.top(statsTopN) {
+		fmt.Fprintf(&b, "%d\t%s\n", v.count, v.value)
+	}
+	return b.String()
+}
+
+// formatStatNum formats a number without unnecessary digits.
+func formatStatNum(num float64) string {
+	return strconv.FormatFloat(num, 'f', -1, 64)
+}
+
+// columnStats returns the statistics of the specified column.
+// Header lines(SkipLines and Header) are not included.
+func (m *Document) columnStats(ctx context.Context, cursor int) (*columnStat, error) {
+	stat := newColumnStat(cursor)
+	err := m.rangeLines(ctx, m.firstLine(), m.BufEndNum(), func(lN int, line []byte) error {
+		str, err := m.bytesColumnString(line, cursor)
+		if err != nil {
+			// Lines without the column are not counted.
+			return nil
+		}
+		stat.add(str)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// statsDocument returns a new document that displays the statistics.
+func (m *Document) statsDocument(stat *columnStat) (*Document, error) {
+	doc, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	doc.FileName = m.FileName
+	doc.Caption = fmt.Sprintf("(stats:%d)%s", stat.cursor, m.FileName)
+	doc.preventReload = true
+	if err := doc.ControlReader(bytes.NewBufferString(stat.String()), nil); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// eventColumnStats represents the column statistics event.
+type eventColumnStats struct {
+	tcell.EventTime
+}
+
+// sendColumnStats fires the eventColumnStats event.
+func (root *Root) sendColumnStats() {
+	if !root.Doc.ColumnMode {
+		root.setMessage("stats: column mode is not enabled")
+		return
+	}
+	ev := &eventColumnStats{}
+	ev.SetEventNow()
+	root.postEvent(ev)
+}
+
+// columnStats computes the statistics of the column of the cursor
+// and adds the result as a new document.
+func (root *Root) columnStats(ctx context.Context) {
+	m := root.Doc
+	if !m.ColumnMode {
+		root.setMessage("stats: column mode is not enabled")
+		return
+	}
+
+	cursor := m.columnCursor
+	root.setMessagef("stats:column %d (%v)Cancel", cursor, strings.Join(root.cancelKeys, ","))
+	eg, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eg.Go(func() error {
+		return root.cancelWait(cancel)
+	})
+
+	var stat *columnStat
+	eg.Go(func() error {
+		defer root.sendSearchQuit()
+		var err error
+		stat, err = m.columnStats(ctx, cursor)
+		return err
+	})
+
+	if err := eg.Wait(); err != nil {
+		root.setMessageLogf("stats: %s", err)
+		return
+	}
+
+	doc, err := m.statsDocument(stat)
+	if err != nil {
+		root.setMessageLogf("stats: %s", err)
+		return
+	}
+	root.addDocument(doc)
+	root.setMessagef("stats of column %d", cursor)
+}
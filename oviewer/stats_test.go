@@ -0,0 +1,133 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDocument_columnStats(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		count    int
+		empty    int
+		distinct int
+		numCount int
+		min      float64
+		max      float64
+		sum      float64
+		top      []statValue
+	}
+	tests := []struct {
+		name   string
+		str    string
+		header int
+		cursor int
+		want   want
+	}{
+		{
+			name:   "numeric",
+			str:    "name,num\na,1\nb,2\nc,2\nd,5\n",
+			header: 1,
+			cursor: 1,
+			want: want{
+				count:    4,
+				empty:    0,
+				distinct: 3,
+				numCount: 4,
+				min:      1,
+				max:      5,
+				sum:      10,
+				top:      []statValue{{"2", 2}, {"1", 1}, {"5", 1}},
+			},
+		},
+		{
+			name:   "string",
+			str:    "x,1\ny,\nx,3\nz\n",
+			header: 0,
+			cursor: 0,
+			want: want{
+				count:    3,
+				empty:    0,
+				distinct: 2,
+				numCount: 0,
+				top:      []statValue{{"x", 2}, {"y", 1}},
+			},
+		},
+		{
+			name:   "missingColumn",
+			str:    "x,1\ny\nx,,\n",
+			header: 0,
+			cursor: 1,
+			want: want{
+				count:    2,
+				empty:    1,
+				distinct: 1,
+				numCount: 1,
+				min:      1,
+				max:      1,
+				sum:      1,
+				top:      []statValue{{"1", 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.ColumnDelimiter = ","
+			m.Header = tt.header
+			if err := m.ControlReader(bytes.NewBufferString(tt.str), nil); err != nil {
+				t.Fatal(err)
+			}
+			for !m.BufEOF() {
+			}
+			got, err := m.columnStats(context.Background(), tt.cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.count != tt.want.count {
+				t.Errorf("Document.columnStats() count = %v, want %v", got.count, tt.want.count)
+			}
+			if got.empty != tt.want.empty {
+				t.Errorf("Document.columnStats() empty = %v, want %v", got.empty, tt.want.empty)
+			}
+			if len(got.counts) != tt.want.distinct {
+				t.Errorf("Document.columnStats() distinct = %v, want %v", len(got.counts), tt.want.distinct)
+			}
+			if got.numCount != tt.want.numCount {
+				t.Errorf("Document.columnStats() numCount = %v, want %v", got.numCount, tt.want.numCount)
+			}
+			if got.min != tt.want.min || got.max != tt.want.max || got.sum != tt.want.sum {
+				t.Errorf("Document.columnStats() min,max,sum = %v,%v,%v, want %v,%v,%v", got.min, got.max, got.sum, tt.want.min, tt.want.max, tt.want.sum)
+			}
+			if top := got.top(statsTopN); !reflect.DeepEqual(top, tt.want.top) {
+				t.Errorf("Document.columnStats() top = %v, want %v", top, tt.want.top)
+			}
+		})
+	}
+}
+
+func TestDocument_columnStatsCancel(t *testing.T) {
+	t.Parallel()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnDelimiter = ","
+	if err := m.ControlReader(bytes.NewBufferString("a,1\nb,2\n"), nil); err != nil {
+		t.Fatal(err)
+	}
+	for !m.BufEOF() {
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.columnStats(ctx, 0); err == nil {
+		t.Errorf("Document.columnStats() expected error")
+	}
+}
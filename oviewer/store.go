@@ -22,6 +22,8 @@ func NewStore() *store {
 		chunks: []*chunk{
 			NewChunk(0),
 		},
+		sep:     '\n',
+		loading: -1,
 	}
 }
 
@@ -78,19 +80,20 @@ func (s *store) swapLoadedFile(chunkNum int) {
 		return
 	}
 	if s.loadedChunks.Len() >= MemoryLimitFile {
-		k, _, _ := s.loadedChunks.GetOldest()
-		if chunkNum != k {
+		// The oldest is not found if the limit is 0.
+		if k, _, ok := s.loadedChunks.GetOldest(); ok && chunkNum != k {
 			s.unloadChunk(k)
 		}
 	}
 	if s.loadedChunks.Add(chunkNum, struct{}{}) {
 		log.Println("loadChunksFile evicted!")
 	}
+	s.touchBudget(chunkNum)
 }
 
 // loadChunksMem adds non-regular file chunks to memory.
 func (s *store) loadChunksMem(chunkNum int) {
-	if MemoryLimit < 0 {
+	if MemoryLimit < 0 && MemoryBudget <= 0 {
 		return
 	}
 	if chunkNum == 0 {
@@ -103,25 +106,44 @@ func (s *store) loadChunksMem(chunkNum int) {
 
 // evictChunksMem evicts non-regular file chunks from memory.
 // Change the start position after unloading.
+// The chunks of files are unloaded first if MemoryBudget is exceeded,
+// because the chunks of non-regular files cannot be read again.
 func (s *store) evictChunksMem(chunkNum int) {
-	if MemoryLimit < 0 {
+	if chunkNum == 0 {
 		return
 	}
-	if chunkNum == 0 {
+	evictOverBudget(s, chunkNum)
+	if !s.overLimitMem() {
 		return
 	}
-	if s.loadedChunks.Len() < MemoryLimit {
+	k, _, ok := s.loadedChunks.GetOldest()
+	if !ok {
 		return
 	}
-	k, _, _ := s.loadedChunks.GetOldest()
 	s.unloadChunk(k)
 	atomic.StoreInt32(&s.startNum, int32((k+1)*ChunkSize))
 }
 
+// overLimitMem returns true if the non-regular file chunks exceed
+// MemoryLimit or the memory used by this document alone exceeds MemoryBudget.
+// The usage of other documents does not discard the chunks.
+func (s *store) overLimitMem() bool {
+	if MemoryLimit >= 0 && s.loadedChunks.Len() >= MemoryLimit {
+		return true
+	}
+	return MemoryBudget > 0 && atomic.LoadInt64(&s.loadedBytes) > MemoryBudget
+}
+
 // unloadChunk unloads the chunk from memory.
 func (s *store) unloadChunk(chunkNum int) {
 	s.loadedChunks.Remove(chunkNum)
-	s.chunks[chunkNum].lines = nil
+	budgetChunks.Remove(budgetChunk{s: s, chunkNum: chunkNum})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunk := s.chunks[chunkNum]
+	chunk.lines = nil
+	s.subBytes(chunk)
+	s.unmapChunk(chunk)
 }
 
 // lastChunkNum returns the last chunk number.
@@ -155,12 +177,37 @@ func (s *store) isLoadedChunk(chunkNum int, isFile bool) bool {
 	if chunkNum == 0 {
 		return true
 	}
-	if !isFile {
+	if !isFile || s.isPinned(chunkNum) {
 		return true
 	}
+	if s.isStaleMapped(chunkNum) {
+		return false
+	}
 	return s.loadedChunks.Contains(chunkNum)
 }
 
+// pin keeps the chunks from start to end (including end) in memory.
+// The chunks must be loaded.
+func (s *store) pin(start int, end int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pinned == nil {
+		s.pinned = make(map[int]bool)
+	}
+	for chunkNum := max(start, 1); chunkNum <= end; chunkNum++ {
+		s.pinned[chunkNum] = true
+		s.loadedChunks.Remove(chunkNum)
+		budgetChunks.Remove(budgetChunk{s: s, chunkNum: chunkNum})
+	}
+}
+
+// isPinned returns true if the chunk is kept in memory.
+func (s *store) isPinned(chunkNum int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pinned[chunkNum]
+}
+
 // isContinueRead returns whether to continue reading.
 func (s *store) isContinueRead(limit int) bool {
 	if limit < 0 {
@@ -195,13 +242,14 @@ func chunkLineNum(n int) (int, int) {
 // Read and fill the number of lines from start to end in chunk.
 // If addLines is true, increment the number of lines read (update endNum).
 func (s *store) readLines(chunk *chunk, reader *bufio.Reader, start int, end int, updateNum bool) error {
+	sep, _ := s.separator()
 	var line bytes.Buffer
 	var isPrefix bool
 	for num := start; num < end; {
 		if atomic.LoadInt32(&s.readCancel) == 1 {
 			break
 		}
-		buf, err := reader.ReadSlice('\n')
+		buf, err := reader.ReadSlice(sep)
 		if errors.Is(err, bufio.ErrBufferFull) {
 			isPrefix = true
 			err = nil
@@ -229,6 +277,7 @@ func (s *store) readLines(chunk *chunk, reader *bufio.Reader, start int, end int
 
 // countLines counts the number of lines and the size of the buffer.
 func (s *store) countLines(reader *bufio.Reader, start int, end int) (int, int, error) {
+	sep, _ := s.separator()
 	count := 0
 	size := 0
 	buf := make([]byte, bufSize)
@@ -242,13 +291,13 @@ func (s *store) countLines(reader *bufio.Reader, start int, end int) (int, int,
 		}
 
 		lSize := bufLen
-		lCount := bytes.Count(buf[:bufLen], []byte("\n"))
+		lCount := bytes.Count(buf[:bufLen], []byte{sep})
 		// If it exceeds ChunkSize, Re-aggregate size and count.
 		if num+lCount > ChunkSize {
 			lSize = 0
 			lCount = ChunkSize - num
 			for i := 0; i < lCount; i++ {
-				p := bytes.IndexByte(buf[lSize:bufLen], '\n')
+				p := bytes.IndexByte(buf[lSize:bufLen], sep)
 				lSize += p + 1
 			}
 		}
@@ -259,14 +308,14 @@ func (s *store) countLines(reader *bufio.Reader, start int, end int) (int, int,
 		if num >= ChunkSize {
 			// no newline at the end of the file.
 			if bufLen < bufSize {
-				p := bytes.LastIndex(buf[:bufLen], []byte("\n"))
+				p := bytes.LastIndexByte(buf[:bufLen], sep)
 				size -= bufLen - p - 1
 			}
 			break
 		}
 		// no newline at the end of the file.
 		if bufLen < bufSize {
-			p := bytes.LastIndex(buf[:bufLen], []byte("\n"))
+			p := bytes.LastIndexByte(buf[:bufLen], sep)
 			if p+1 < bufLen {
 				count++
 				atomic.StoreInt32(&s.noNewlineEOF, 1)
@@ -296,6 +345,7 @@ func (s *store) appendOnly(chunk *chunk, line []byte) {
 	dst := make([]byte, size)
 	copy(dst, line)
 	chunk.lines = append(chunk.lines, dst)
+	s.addBytes(chunk, size)
 }
 
 // appendLine appends to the line of the chunk.
@@ -315,6 +365,7 @@ func (s *store) appendLine(chunk *chunk, line []byte) {
 	dst := make([]byte, size)
 	copy(dst, line)
 	chunk.lines = append(chunk.lines, dst)
+	s.addBytes(chunk, size)
 }
 
 // joinLast joins the new content to the last line.
@@ -335,8 +386,9 @@ func (s *store) joinLast(chunk *chunk, line []byte) bool {
 	dst = append(dst, line...)
 	s.size += int64(size)
 	chunk.lines[num] = dst
+	s.addBytes(chunk, size)
 
-	if line[len(line)-1] == '\n' {
+	if line[len(line)-1] == s.sep {
 		atomic.StoreInt32(&s.noNewlineEOF, 0)
 	}
 	return true
@@ -357,6 +409,7 @@ func (s *store) appendFormFeed(chunk *chunk) {
 			feed = fmt.Sprintf("%sTime: %s", FormFeed, time.Now().Format(time.RFC3339))
 		}
 		s.appendLine(chunk, []byte(feed))
+		s.addSnapshot()
 	}
 }
 
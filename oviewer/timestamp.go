@@ -0,0 +1,287 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+// timestampRegexp matches common timestamps of logs.
+// RFC3339 (e.g. "2006-01-02T15:04:05.000Z", "2006-01-02 15:04:05,000"),
+// Apache (e.g. "02/Jan/2006:15:04:05 -0700") and syslog (e.g. "Jan  2 15:04:05").
+var timestampRegexp = regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?` +
+	`|\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}` +
+	`|[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`)
+
+// epochRegexp matches UNIX time in seconds (e.g. 1700000000, 1700000000.123) or milliseconds (e.g. 1700000000123).
+// Only the value at the beginning of the line or of a field (e.g. ts=1700000000, "ts":1700000000, [1700000000])
+// is matched, so that IDs and counts in the message are not regarded as time.
+var epochRegexp = regexp.MustCompile(`(?:^|[=:\[]\s*"?)(1\d{9})(\d{3}|\.\d{1,9})?\b`)
+
+// timeZoneRegexp matches the input that has the time zone.
+var timeZoneRegexp = regexp.MustCompile(`(?:Z|[+-]\d{2}:?\d{2})$`)
+
+// timeScanLines is the number of lines to scan for the line with a timestamp.
+const timeScanLines = 100
+
+// timeDeltaWidth is the width of the relative time gutter.
+const timeDeltaWidth = 8
+
+// prevTimestamp is the timestamp of the previous line cached for the relative time.
+type prevTimestamp struct {
+	t  time.Time
+	ok bool
+}
+
+// lineTimestamp returns the first timestamp in the line.
+// UNIX time is used only if there is no other timestamp.
+func lineTimestamp(line []byte) (time.Time, bool) {
+	if match := timestampRegexp.Find(line); match != nil {
+		return parseTime(strings.Replace(string(match), ",", ".", 1))
+	}
+	match := epochRegexp.FindSubmatch(line)
+	if match == nil {
+		return time.Time{}, false
+	}
+	return parseEpoch(string(match[1]), string(match[2]))
+}
+
+// parseEpoch parses UNIX time of seconds and the fraction (".123") or milliseconds ("123").
+func parseEpoch(sec string, frac string) (time.Time, bool) {
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var nsec int64
+	switch {
+	case strings.HasPrefix(frac, "."):
+		f, err := strconv.ParseFloat("0"+frac, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		nsec = int64(f * float64(time.Second))
+	case frac != "":
+		ms, err := strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		nsec = ms * int64(time.Millisecond)
+	}
+	return time.Unix(s, nsec).UTC(), true
+}
+
+// isTimeInput returns true if the input of goto is a time (e.g. 14:32, 2026-10-18T09:00, @1700000000).
+func isTimeInput(input string) bool {
+	if strings.HasPrefix(input, "@") || strings.Contains(input, ":") {
+		return true
+	}
+	_, ok := parseTime(input)
+	return ok && strings.ContainsAny(input, "-/")
+}
+
+// parseTimeInput parses the input of goto as a time.
+// The date and the location that are not in the input are complemented by ref,
+// the timestamp of the current line.
+func parseTimeInput(input string, ref time.Time) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "@") {
+		if t, ok := lineTimestamp([]byte(input[1:])); ok {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, input)
+	}
+	t, ok := parseTime(input)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, input)
+	}
+	loc := t.Location()
+	if !timeZoneRegexp.MatchString(input) {
+		loc = ref.Location()
+	}
+	year, month, day := t.Date()
+	// The layout without a date is parsed as year 0.
+	if year == 0 && month == time.January && day == 1 {
+		year, month, day = ref.Date()
+	}
+	return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+}
+
+// timestampAt returns the first timestamp from lN within timeScanLines lines and its line number.
+func (m *Document) timestampAt(ctx context.Context, lN int) (time.Time, int, bool, error) {
+	var ts time.Time
+	tsLN := -1
+	err := m.rangeLines(ctx, lN, lN+timeScanLines, func(n int, line []byte) error {
+		if tsLN >= 0 {
+			return nil
+		}
+		if t, ok := lineTimestamp(line); ok {
+			ts, tsLN = t, n
+		}
+		return nil
+	})
+	return ts, tsLN, tsLN >= 0, err
+}
+
+// searchTime returns the number of the first line whose timestamp is at or after t.
+// The lines are binary searched on the assumption that the timestamps are in order,
+// so only the chunks to be compared are loaded.
+func (m *Document) searchTime(ctx context.Context, t time.Time) (int, error) {
+	lo, hi := m.firstLine(), m.BufEndNum()
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		ts, tsLN, ok, err := m.timestampAt(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case !ok:
+			lo = mid + 1
+		case ts.Before(t):
+			lo = tsLN + 1
+		default:
+			hi = mid
+		}
+	}
+	if lo >= m.BufEndNum() {
+		return 0, fmt.Errorf("%w: after %s", ErrNoTimestamp, t.Format(time.RFC3339))
+	}
+	if _, tsLN, ok, err := m.timestampAt(ctx, lo); err == nil && ok {
+		return tsLN, nil
+	}
+	return lo, nil
+}
+
+// goTime moves to the first line whose timestamp is at or after the input time.
+// The search can be canceled with the cancel keys.
+func (root *Root) goTime(ctx context.Context, input string) {
+	m := root.Doc
+	root.setMessagef("goto time: %s (%v)Cancel", input, strings.Join(root.cancelKeys, ","))
+	eg, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eg.Go(func() error {
+		return root.cancelWait(cancel)
+	})
+
+	var t time.Time
+	lN := 0
+	eg.Go(func() error {
+		defer root.sendSearchQuit()
+		ref, _, ok, err := m.timestampAt(ctx, m.topLN+m.firstLine())
+		if err == nil && !ok {
+			ref, _, ok, err = m.timestampAt(ctx, m.firstLine())
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNoTimestamp
+		}
+		t, err = parseTimeInput(input, ref)
+		if err != nil {
+			return err
+		}
+		lN, err = m.searchTime(ctx, t)
+		return err
+	})
+
+	if err := eg.Wait(); err != nil {
+		root.setMessagef("goto time: %s", err)
+		return
+	}
+	lN = m.moveLine(lN - m.firstLine())
+	root.setMessagef("Moved to line %d (%s)", lN+1, t.Format(time.RFC3339))
+}
+
+// lineTime returns the timestamp of the displayed line.
+func (m *Document) lineTime(lN int) (time.Time, bool) {
+	line, valid := m.getLineC(lN, m.TabWidth)
+	if !valid {
+		return time.Time{}, false
+	}
+	return lineTimestamp([]byte(line.str))
+}
+
+// timeDelta returns the time from the previous line with a timestamp to the line.
+func (m *Document) timeDelta(lN int) (time.Duration, bool) {
+	t, ok := m.lineTime(lN)
+	if !ok {
+		return 0, false
+	}
+	prev, ok := m.prevTime(lN)
+	if !ok {
+		return 0, false
+	}
+	return t.Sub(prev), true
+}
+
+// prevTime returns the timestamp of the previous line with a timestamp.
+// The result is cached for each line, so that redrawing does not scan the lines again.
+func (m *Document) prevTime(lN int) (time.Time, bool) {
+	if prev, ok := m.timeCache.Get(lN); ok {
+		return prev.t, prev.ok
+	}
+	var prev prevTimestamp
+	for n := lN - 1; n >= max(0, lN-timeScanLines); n-- {
+		if t, ok := m.lineTime(n); ok {
+			prev = prevTimestamp{t: t, ok: true}
+			break
+		}
+		// The line without a timestamp has the same previous timestamp.
+		if p, ok := m.timeCache.Peek(n); ok {
+			prev = p
+			break
+		}
+	}
+	m.timeCache.Add(lN, prev)
+	return prev.t, prev.ok
+}
+
+// formatTimeDelta returns the relative time in a short form that fits in timeDeltaWidth.
+// e.g. "+0.250s", "+12.5s", "+3m05s", "+2h03m", "+1d02h".
+func formatTimeDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%s%.3fs", sign, d.Seconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%s%.1fs", sign, d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%s%dm%02ds", sign, int(d/time.Minute), int(d%time.Minute/time.Second))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%s%dh%02dm", sign, int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return fmt.Sprintf("%s%dd%02dh", sign, int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+}
+
+// blankTimeDelta should be blank for the relative time.
+func (root *Root) blankTimeDelta(y int) {
+	numC := StrToContents(strings.Repeat(" ", timeDeltaWidth), root.Doc.TabWidth)
+	root.setContentString(root.scr.timeX, y, numC)
+}
+
+// drawTimeDelta draws the relative time from the previous line.
+func (root *Root) drawTimeDelta(lN int, y int) {
+	m := root.Doc
+	d, ok := m.timeDelta(lN)
+	if !ok {
+		root.blankTimeDelta(y)
+		return
+	}
+	numC := StrToContents(fmt.Sprintf("%*s ", timeDeltaWidth-1, formatTimeDelta(d)), m.TabWidth)
+	for i := 0; i < len(numC); i++ {
+		numC[i].style = applyStyle(tcell.StyleDefault, root.StyleLineNumber)
+	}
+	root.setContentString(root.scr.timeX, y, numC)
+}
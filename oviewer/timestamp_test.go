@@ -0,0 +1,243 @@
+package oviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_lineTimestamp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "rfc3339",
+			line:   `{"time":"2026-10-18T09:00:01+09:00","level":"info"}`,
+			want:   time.Date(2026, 10, 18, 0, 0, 1, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "space and milliseconds",
+			line:   "2026-10-18 09:00:01.500 INFO start",
+			want:   time.Date(2026, 10, 18, 9, 0, 1, 500000000, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "syslog",
+			line:   "Oct 18 03:00:00 host sshd[1]: session opened",
+			want:   time.Date(0, 10, 18, 3, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "apache",
+			line:   `::1 - - [18/Oct/2026:03:00:00 +0000] "GET / HTTP/1.1" 200 45`,
+			want:   time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "epoch",
+			line:   "ts=1760756400 msg=start",
+			want:   time.Date(2025, 10, 18, 3, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "epoch milliseconds",
+			line:   "ts=1760756400250 msg=start",
+			want:   time.Date(2025, 10, 18, 3, 0, 0, 250000000, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "epoch fraction",
+			line:   "1760756400.5 start",
+			want:   time.Date(2025, 10, 18, 3, 0, 0, 500000000, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "epoch json",
+			line:   `{"ts": 1760756400,"msg":"start"}`,
+			want:   time.Date(2025, 10, 18, 3, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "number in message",
+			line:   "user 1760756400 logged in",
+			wantOK: false,
+		},
+		{
+			name:   "no timestamp",
+			line:   "\tat main.main(main.go:12345678901)",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := lineTimestamp([]byte(tt.line))
+			if ok != tt.wantOK {
+				t.Fatalf("lineTimestamp() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("lineTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isTimeInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "14:32", want: true},
+		{input: "2026-10-18T09:00", want: true},
+		{input: "2026-10-18", want: true},
+		{input: "@1760756400", want: true},
+		{input: "123", want: false},
+		{input: "50%", want: false},
+		{input: ".5", want: false},
+	}
+	for _, tt := range tests {
+		if got := isTimeInput(tt.input); got != tt.want {
+			t.Errorf("isTimeInput(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func Test_parseTimeInput(t *testing.T) {
+	t.Parallel()
+	jst := time.FixedZone("JST", 9*60*60)
+	ref := time.Date(2026, 10, 18, 12, 0, 0, 0, jst)
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr error
+	}{
+		{name: "time only", input: "14:32", want: time.Date(2026, 10, 18, 14, 32, 0, 0, jst)},
+		{name: "seconds", input: "03:00:05", want: time.Date(2026, 10, 18, 3, 0, 5, 0, jst)},
+		{name: "date and time", input: "2026-10-17T09:00", want: time.Date(2026, 10, 17, 9, 0, 0, 0, jst)},
+		{name: "date", input: "2026-10-17", want: time.Date(2026, 10, 17, 0, 0, 0, 0, jst)},
+		{name: "time zone", input: "2026-10-17T09:00:00Z", want: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)},
+		{name: "epoch", input: "@1760756400", want: time.Date(2025, 10, 18, 3, 0, 0, 0, time.UTC)},
+		{name: "invalid", input: "25:99", wantErr: ErrInvalidTime},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseTimeInput(tt.input, ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseTimeInput() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !got.Equal(tt.want) {
+				t.Errorf("parseTimeInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatTimeDelta(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "+0.000s"},
+		{d: 250 * time.Millisecond, want: "+0.250s"},
+		{d: 12500 * time.Millisecond, want: "+12.5s"},
+		{d: 3*time.Minute + 5*time.Second, want: "+3m05s"},
+		{d: 2*time.Hour + 3*time.Minute, want: "+2h03m"},
+		{d: 26 * time.Hour, want: "+1d02h"},
+		{d: -1500 * time.Millisecond, want: "-1.5s"},
+	}
+	for _, tt := range tests {
+		got := formatTimeDelta(tt.d)
+		if got != tt.want {
+			t.Errorf("formatTimeDelta(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+		if len(got) > timeDeltaWidth-1 {
+			t.Errorf("formatTimeDelta(%v) = %q, wider than %d", tt.d, got, timeDeltaWidth-1)
+		}
+	}
+}
+
+func TestDocument_searchTime(t *testing.T) {
+	t.Parallel()
+	// A line with a timestamp every second followed by a line without a timestamp.
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	var b strings.Builder
+	b.WriteString("header\n")
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&b, "%s line %d\n", start.Add(time.Duration(i)*time.Second).Format("2006-01-02 15:04:05"), i)
+		b.WriteString("\tcontinued\n")
+	}
+	fileName := filepath.Join(t.TempDir(), "time.log")
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	tests := []struct {
+		name    string
+		t       time.Time
+		want    int
+		wantErr error
+	}{
+		{name: "first", t: start.Add(-time.Hour), want: 1},
+		{name: "exact", t: start.Add(3 * time.Hour), want: 1 + 2*10800},
+		{name: "between", t: start.Add(3*time.Hour + 500*time.Millisecond), want: 1 + 2*10801},
+		{name: "last", t: start.Add(19999 * time.Second), want: 1 + 2*19999},
+		{name: "after the end", t: start.Add(20000 * time.Second), wantErr: ErrNoTimestamp},
+	}
+	for _, tt := range tests {
+		got, err := m.searchTime(context.Background(), tt.t)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: searchTime() error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%s: searchTime() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDocument_timeDelta(t *testing.T) {
+	t.Parallel()
+	fileName := filepath.Join(t.TempDir(), "time.log")
+	str := "2026-10-18 09:00:00 start\n\tcontinued\n\tcontinued\n2026-10-18 09:00:02 stop\n"
+	if err := os.WriteFile(fileName, []byte(str), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := openIndexTestDocument(t, fileName)
+	tests := []struct {
+		lN     int
+		want   time.Duration
+		wantOK bool
+	}{
+		{lN: 0, wantOK: false},
+		{lN: 1, wantOK: false},
+		{lN: 3, want: 2 * time.Second, wantOK: true},
+	}
+	// The second time uses the cached previous timestamps.
+	for i := 0; i < 2; i++ {
+		for _, tt := range tests {
+			got, ok := m.timeDelta(tt.lN)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("timeDelta(%d) = %v, %v, want %v, %v", tt.lN, got, ok, tt.want, tt.wantOK)
+			}
+		}
+	}
+	if prev, ok := m.timeCache.Peek(3); !ok || !prev.ok {
+		t.Errorf("the previous timestamp of line 3 is not cached")
+	}
+}
@@ -4,14 +4,23 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
+	"compress/zlib"
 	"errors"
+	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
 
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/snappy"
 	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4"
 	"github.com/ulikunitz/xz"
 )
 
+// zlibProbeSize is the size of the data inflated to confirm the zlib header.
+const zlibProbeSize = 512
+
 // Compressed represents the type of compression.
 type Compressed int
 
@@ -28,6 +37,14 @@ const (
 	LZ4
 	// XZ is xz compressed format.
 	XZ
+	// BROTLI is brotli compressed format.
+	BROTLI
+	// ZLIB is zlib compressed format.
+	ZLIB
+	// LZIP is lzip compressed format.
+	LZIP
+	// SNAPPY is snappy framing format.
+	SNAPPY
 )
 
 func compressType(header []byte) Compressed {
@@ -42,10 +59,88 @@ func compressType(header []byte) Compressed {
 		return LZ4
 	case bytes.Equal(header[:7], []byte{0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x0, 0x0}):
 		return XZ
+	case bytes.Equal(header[:5], []byte{0x4c, 0x5a, 0x49, 0x50, 0x01}):
+		return LZIP
+	case bytes.Equal(header[:7], []byte{0xff, 0x06, 0x00, 0x00, 0x73, 0x4e, 0x61}):
+		return SNAPPY
+	case isZlibHeader(header) && isZlibData(header):
+		return ZLIB
+	}
+	return UNCOMPRESSED
+}
+
+// isZlibHeader returns true if the header is a zlib header with deflate and without a preset dictionary.
+func isZlibHeader(header []byte) bool {
+	if header[0] != 0x78 || header[1]&0x20 != 0 {
+		return false
+	}
+	return (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// isZlibData returns true if the beginning of the data can be inflated.
+// Text starting with "x^" also has a valid zlib header,
+// so the header alone is not enough to decide.
+// The data may end in the middle of the stream.
+func isZlibData(data []byte) bool {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	_, err = io.Copy(io.Discard, zr)
+	return err == nil || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// compressTypeByName returns the compression type from the file extension.
+// It is used for formats without magic bytes.
+func compressTypeByName(name string) Compressed {
+	if strings.EqualFold(filepath.Ext(name), ".br") {
+		return BROTLI
 	}
 	return UNCOMPRESSED
 }
 
+// detectCompressType returns the compression type of the header and the file name.
+// The format specified by CompressFormat takes precedence over the detection.
+func detectCompressType(header []byte, name string) Compressed {
+	if CompressFormat != "" {
+		if cFormat, err := ParseCompressed(CompressFormat); err == nil {
+			return cFormat
+		}
+	}
+	if cFormat := compressType(header); cFormat != UNCOMPRESSED {
+		return cFormat
+	}
+	return compressTypeByName(name)
+}
+
+// ParseCompressed returns the compressed format of the name.
+// "none" means uncompressed.
+func ParseCompressed(name string) (Compressed, error) {
+	switch strings.ToLower(name) {
+	case "none", "uncompressed":
+		return UNCOMPRESSED, nil
+	case "gzip", "gz":
+		return GZIP, nil
+	case "bzip2", "bz2":
+		return BZIP2, nil
+	case "zstd", "zst":
+		return ZSTD, nil
+	case "lz4":
+		return LZ4, nil
+	case "xz":
+		return XZ, nil
+	case "brotli", "br":
+		return BROTLI, nil
+	case "zlib":
+		return ZLIB, nil
+	case "lzip", "lz":
+		return LZIP, nil
+	case "snappy", "sz":
+		return SNAPPY, nil
+	}
+	return UNCOMPRESSED, fmt.Errorf("%w: %s", ErrUnknownCompress, name)
+}
+
 // String returns the string representation of the compressed format.
 func (c Compressed) String() string {
 	switch c {
@@ -59,22 +154,36 @@ func (c Compressed) String() string {
 		return "LZ4"
 	case XZ:
 		return "XZ"
+	case BROTLI:
+		return "BROTLI"
+	case ZLIB:
+		return "ZLIB"
+	case LZIP:
+		return "LZIP"
+	case SNAPPY:
+		return "SNAPPY"
 	}
 	return "UNCOMPRESSED"
 }
 
 // uncompressedReader returns a reader for the uncompressed format.
-func uncompressedReader(reader io.Reader, seekable bool) (Compressed, io.Reader) {
-	buf := [7]byte{}
-	n, err := io.ReadAtLeast(reader, buf[:], len(buf))
+// name is used to detect formats without magic bytes.
+func uncompressedReader(reader io.Reader, name string, seekable bool) (Compressed, io.Reader) {
+	buf := make([]byte, zlibProbeSize)
+	n, err := io.ReadAtLeast(reader, buf[:7], 7)
 	if err != nil {
 		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 			return UNCOMPRESSED, bytes.NewReader(buf[:n])
 		}
 		return UNCOMPRESSED, bytes.NewReader(nil)
 	}
+	// Read more to inflate the beginning of the zlib candidate.
+	if isZlibHeader(buf[:n]) {
+		nn, _ := io.ReadFull(reader, buf[n:])
+		n += nn
+	}
 
-	cFormat := compressType(buf[:7])
+	cFormat := detectCompressType(buf[:n], name)
 	if seekable && cFormat == UNCOMPRESSED {
 		return UNCOMPRESSED, nil
 	}
@@ -90,18 +199,52 @@ func compressedFormatReader(cFormat Compressed, reader io.Reader) io.Reader {
 	var err error
 	switch cFormat {
 	case GZIP:
-		r, err = gzip.NewReader(reader)
+		var gr *gzip.Reader
+		gr, err = gzip.NewReader(reader)
+		r = &trailingReader{r: gr, garbage: gzip.ErrHeader}
 	case BZIP2:
 		r = bzip2.NewReader(reader)
 	case ZSTD:
-		r, err = zstd.NewReader(reader)
+		var zr *zstd.Decoder
+		zr, err = zstd.NewReader(reader)
+		r = &trailingReader{r: zr, garbage: zstd.ErrMagicMismatch}
 	case LZ4:
 		r = lz4.NewReader(reader)
 	case XZ:
 		r, err = xz.NewReader(reader)
+	case BROTLI:
+		r = brotli.NewReader(reader)
+	case ZLIB:
+		r, err = zlib.NewReader(reader)
+	case LZIP:
+		r = newLzipReader(reader)
+	case SNAPPY:
+		r = snappy.NewReader(reader)
 	}
 	if err != nil || r == nil {
 		r = reader
 	}
 	return r
 }
+
+// trailingReader is a reader that ignores the garbage after the compressed data.
+// Concatenated gzip members and zstd frames are read to the end,
+// but rotated logs may have padding (such as zeros) after them.
+type trailingReader struct {
+	r       io.Reader
+	garbage error
+	read    bool
+}
+
+// Read reads the uncompressed data.
+// The garbage error after reading some data is treated as io.EOF.
+func (t *trailingReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.read = true
+	}
+	if t.read && err != nil && errors.Is(err, t.garbage) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,144 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+const (
+	// lzipHeaderLen is the length of the lzip member header.
+	lzipHeaderLen = 6
+	// lzipTrailerLen is the length of the lzip member trailer.
+	lzipTrailerLen = 20
+)
+
+// lzipMagic is the magic bytes and the version of lzip.
+var lzipMagic = []byte{0x4c, 0x5a, 0x49, 0x50, 0x01}
+
+// lzipReader is a reader for lzip files.
+// An lzip file consists of one or more members,
+// each of which is an LZMA stream with a header and a trailer.
+type lzipReader struct {
+	r     *bufio.Reader
+	lr    *lzma.Reader
+	err   error
+	crc   uint32
+	size  uint64
+	first bool
+}
+
+// newLzipReader returns a reader for lzip files.
+func newLzipReader(r io.Reader) *lzipReader {
+	return &lzipReader{
+		r:     bufio.NewReader(r),
+		first: true,
+	}
+}
+
+// Read reads the uncompressed data.
+func (z *lzipReader) Read(p []byte) (int, error) {
+	for {
+		if z.err != nil {
+			return 0, z.err
+		}
+		if z.lr == nil {
+			z.err = z.nextMember()
+			continue
+		}
+		n, err := z.lr.Read(p)
+		z.crc = crc32.Update(z.crc, crc32.IEEETable, p[:n])
+		z.size += uint64(n)
+		if errors.Is(err, io.EOF) {
+			z.err = z.readTrailer()
+			z.lr = nil
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// nextMember starts reading the next member.
+// Data after the last member is ignored.
+func (z *lzipReader) nextMember() error {
+	header, err := z.r.Peek(lzipHeaderLen)
+	if err != nil || !bytes.Equal(header[:len(lzipMagic)], lzipMagic) {
+		if z.first {
+			return ErrInvalidLzip
+		}
+		return io.EOF
+	}
+	z.first = false
+	dictSize := lzipDictSize(header[5])
+	if _, err := z.r.Discard(lzipHeaderLen); err != nil {
+		return err
+	}
+
+	// Make the classic LZMA header, because lzip uses fixed properties
+	// and the end of stream marker instead of the size.
+	lzmaHeader := make([]byte, lzma.HeaderLen)
+	lzmaHeader[0] = 0x5d // lc=3, lp=0, pb=2
+	binary.LittleEndian.PutUint32(lzmaHeader[1:], dictSize)
+	for i := 5; i < lzma.HeaderLen; i++ {
+		lzmaHeader[i] = 0xff
+	}
+	lr, err := lzma.NewReader(&prefixReader{prefix: lzmaHeader, r: z.r})
+	if err != nil {
+		return err
+	}
+	z.lr = lr
+	z.crc = 0
+	z.size = 0
+	return nil
+}
+
+// readTrailer reads and checks the trailer of the member.
+func (z *lzipReader) readTrailer() error {
+	trailer := make([]byte, lzipTrailerLen)
+	if _, err := io.ReadFull(z.r, trailer); err != nil {
+		return ErrInvalidLzip
+	}
+	if binary.LittleEndian.Uint32(trailer[0:4]) != z.crc || binary.LittleEndian.Uint64(trailer[4:12]) != z.size {
+		return ErrInvalidLzip
+	}
+	return nil
+}
+
+// lzipDictSize returns the dictionary size from the coded byte of the header.
+func lzipDictSize(b byte) uint32 {
+	size := uint32(1) << (b & 0x1f)
+	return size - (size/16)*uint32(b>>5)
+}
+
+// prefixReader reads the prefix first and then the reader.
+// It implements io.ByteReader so that the LZMA decoder
+// does not read beyond the end of the stream.
+type prefixReader struct {
+	prefix []byte
+	r      *bufio.Reader
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	if len(p.prefix) > 0 {
+		n := copy(b, p.prefix)
+		p.prefix = p.prefix[n:]
+		return n, nil
+	}
+	return p.r.Read(b)
+}
+
+func (p *prefixReader) ReadByte() (byte, error) {
+	if len(p.prefix) > 0 {
+		b := p.prefix[0]
+		p.prefix = p.prefix[1:]
+		return b, nil
+	}
+	return p.r.ReadByte()
+}
@@ -0,0 +1,397 @@
+package oviewer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// seekHistorySize is the size of the uncompressed data kept
+// so that it can seek back a little without restarting the decompression.
+const seekHistorySize = 64 * 1024
+
+// seekProbeSize is the maximum uncompressed size of the first gzip member
+// decompressed to find the next member.
+const seekProbeSize = 1024 * 1024
+
+const (
+	zstdFrameMagic     = 0xFD2FB528
+	zstdSkippableMask  = 0xFFFFFFF0
+	zstdSkippableMagic = 0x184D2A50
+)
+
+// seekPoint is a position where the decompression can be restarted.
+type seekPoint struct {
+	// offset is the position in the uncompressed data.
+	offset int64
+	// cOffset is the position in the compressed file.
+	cOffset int64
+}
+
+// compressedSeeker is an io.ReadSeeker for the uncompressed contents of a compressed file.
+// While reading, it records the start of each gzip member and zstd frame as a seek point.
+// Seek restarts the decompression from the nearest seek point before the position,
+// so the uncompressed contents do not have to be kept in memory.
+// It is used only for files that have seek points (see hasSeekPoints).
+type compressedSeeker struct {
+	file   io.ReaderAt
+	cr     *countReader
+	r      io.Reader
+	gr     *gzip.Reader
+	zr     *zstd.Decoder
+	points []seekPoint
+	// hist is the most recently read uncompressed data.
+	hist []byte
+	// replay is the part of hist to be read again after seeking back.
+	replay []byte
+	// pos is the current position in the uncompressed data.
+	pos int64
+	// next is the position of the next zstd frame in the compressed file (-1 if unknown).
+	next    int64
+	cFormat Compressed
+	eof     bool
+}
+
+// countReader is a reader that counts the number of bytes read.
+type countReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// fileCompressType returns the compression type of the file without changing the offset.
+func fileCompressType(f io.ReaderAt, name string) Compressed {
+	buf := make([]byte, zlibProbeSize)
+	n, err := f.ReadAt(buf, 0)
+	if n < 7 || (err != nil && !errors.Is(err, io.EOF)) {
+		return UNCOMPRESSED
+	}
+	return detectCompressType(buf[:n], name)
+}
+
+// hasSeekPoints returns whether the compressed file consists of
+// multiple gzip members or zstd frames (e.g. bgzip, pzstd).
+// Only the beginning of the file is examined.
+// Otherwise, seeking backward would decompress the file again from the beginning.
+// Access points inside a single gzip stream (zran) and the block index of xz
+// are not supported, so those files are not seekable.
+func hasSeekPoints(f io.ReaderAt, cFormat Compressed) bool {
+	switch cFormat {
+	case GZIP:
+		cr := &countReader{r: bufio.NewReader(io.NewSectionReader(f, 0, math.MaxInt64))}
+		gr, err := gzip.NewReader(cr)
+		if err != nil {
+			return false
+		}
+		gr.Multistream(false)
+		n, err := io.Copy(io.Discard, io.LimitReader(gr, seekProbeSize+1))
+		if err != nil || n > seekProbeSize {
+			return false
+		}
+		magic := [2]byte{}
+		if _, err := f.ReadAt(magic[:], cr.n); err != nil {
+			return false
+		}
+		return magic[0] == 0x1f && magic[1] == 0x8b
+	case ZSTD:
+		var offset int64
+		for frames := 0; frames < 2; {
+			size, skippable, err := zstdFrameSize(f, offset)
+			if err != nil {
+				return false
+			}
+			if !skippable {
+				frames++
+			}
+			offset += size
+		}
+		return true
+	}
+	return false
+}
+
+// newCompressedSeeker returns a compressedSeeker of the file.
+func newCompressedSeeker(f *os.File, cFormat Compressed) *compressedSeeker {
+	s := &compressedSeeker{
+		file:    f,
+		cFormat: cFormat,
+		hist:    make([]byte, 0, seekHistorySize*2),
+	}
+	s.start(0)
+	return s
+}
+
+// start starts the decompression from the position of the compressed file.
+func (s *compressedSeeker) start(cOffset int64) {
+	s.eof = false
+	s.next = -1
+	s.cr = &countReader{
+		r: bufio.NewReader(io.NewSectionReader(s.file, cOffset, math.MaxInt64-cOffset)),
+		n: cOffset,
+	}
+
+	switch s.cFormat {
+	case GZIP:
+		var err error
+		if s.gr == nil {
+			s.gr, err = gzip.NewReader(s.cr)
+		} else {
+			err = s.gr.Reset(s.cr)
+		}
+		if err != nil {
+			// The end of the file or trailing garbage.
+			s.eof = true
+			return
+		}
+		s.gr.Multistream(false)
+		s.r = s.gr
+	case ZSTD:
+		s.startZstd(cOffset)
+	default:
+		s.r = compressedFormatReader(s.cFormat, s.cr)
+	}
+	if !s.eof && (len(s.points) == 0 || s.points[len(s.points)-1].cOffset < cOffset) {
+		s.points = append(s.points, seekPoint{offset: s.pos, cOffset: cOffset})
+	}
+}
+
+// startZstd starts the decompression of the zstd frame.
+// If the frame size cannot be determined, it decompresses the rest of the file.
+func (s *compressedSeeker) startZstd(cOffset int64) {
+	if s.zr == nil {
+		zr, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			s.eof = true
+			return
+		}
+		s.zr = zr
+	}
+	size, skippable, err := zstdFrameSize(s.file, cOffset)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.eof = true
+			return
+		}
+		if err := s.zr.Reset(s.cr); err != nil {
+			s.eof = true
+			return
+		}
+		s.r = s.zr
+		return
+	}
+	if skippable {
+		s.r = eofReader{}
+		s.next = cOffset + size
+		return
+	}
+	if err := s.zr.Reset(io.NewSectionReader(s.file, cOffset, size)); err != nil {
+		s.eof = true
+		return
+	}
+	s.r = s.zr
+	s.next = cOffset + size
+}
+
+// eofReader is a reader that always returns io.EOF.
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+// nextSegment starts the decompression of the next gzip member or zstd frame.
+// It returns false if there is no next one.
+func (s *compressedSeeker) nextSegment() bool {
+	switch s.cFormat {
+	case GZIP:
+		s.start(s.cr.n)
+	case ZSTD:
+		if s.next < 0 {
+			s.eof = true
+			break
+		}
+		s.start(s.next)
+	default:
+		s.eof = true
+	}
+	return !s.eof
+}
+
+// Read reads the uncompressed data.
+// Like reading a file, it fills p unless it reaches the end,
+// because a short read is regarded as the end of the file when counting lines.
+func (s *compressedSeeker) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		nn, err := s.read(p[n:])
+		n += nn
+		if err != nil {
+			if n > 0 && errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// read reads the uncompressed data from the history or the decompressor.
+func (s *compressedSeeker) read(p []byte) (int, error) {
+	if len(s.replay) > 0 {
+		n := copy(p, s.replay)
+		s.replay = s.replay[n:]
+		s.pos += int64(n)
+		return n, nil
+	}
+
+	for {
+		if s.eof {
+			return 0, io.EOF
+		}
+		n, err := s.r.Read(p)
+		s.record(p[:n])
+		if errors.Is(err, io.EOF) {
+			if !s.nextSegment() && n == 0 {
+				return 0, io.EOF
+			}
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// record records the read data in the history.
+func (s *compressedSeeker) record(p []byte) {
+	s.pos += int64(len(p))
+	if len(s.hist)+len(p) > cap(s.hist) {
+		keep := max(seekHistorySize-len(p), 0)
+		keep = min(keep, len(s.hist))
+		s.hist = append(s.hist[:0], s.hist[len(s.hist)-keep:]...)
+	}
+	if len(p) > seekHistorySize {
+		p = p[len(p)-seekHistorySize:]
+	}
+	s.hist = append(s.hist, p...)
+}
+
+// Seek sets the position of the uncompressed data.
+// Only io.SeekStart and io.SeekCurrent are supported.
+func (s *compressedSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	default:
+		return s.pos, ErrSeekCompressed
+	}
+	if offset < 0 {
+		return s.pos, ErrOutOfRange
+	}
+
+	// The end of the read data.
+	rpos := s.pos + int64(len(s.replay))
+	if back := rpos - offset; back >= 0 && back <= int64(len(s.hist)) {
+		s.replay = s.hist[len(s.hist)-int(back):]
+		s.pos = offset
+		return s.pos, nil
+	}
+
+	point := s.points[0]
+	for _, p := range s.points {
+		if p.offset > offset {
+			break
+		}
+		point = p
+	}
+	s.replay = nil
+	s.pos = rpos
+	if offset < rpos || point.offset > rpos {
+		// Restart from the seek point.
+		s.hist = s.hist[:0]
+		s.pos = point.offset
+		s.start(point.cOffset)
+	}
+	if _, err := io.CopyN(io.Discard, s, offset-s.pos); err != nil && !errors.Is(err, io.EOF) {
+		return s.pos, err
+	}
+	return s.pos, nil
+}
+
+// zstdFrameSize returns the size of the zstd frame starting at the offset.
+// It follows the block headers, so the frame is not decompressed.
+func zstdFrameSize(r io.ReaderAt, offset int64) (size int64, skippable bool, err error) {
+	buf := make([]byte, 8)
+	if _, err := r.ReadAt(buf[:5], offset); err != nil {
+		return 0, false, err
+	}
+	magic := binary.LittleEndian.Uint32(buf)
+	if magic&zstdSkippableMask == zstdSkippableMagic {
+		if _, err := r.ReadAt(buf[:8], offset); err != nil {
+			return 0, true, err
+		}
+		return 8 + int64(binary.LittleEndian.Uint32(buf[4:])), true, nil
+	}
+	if magic != zstdFrameMagic {
+		return 0, false, ErrInvalidZstdFrame
+	}
+
+	fhd := buf[4]
+	single := fhd>>5&1 == 1
+	size = 5
+	if !single {
+		size++ // Window_Descriptor
+	}
+	size += [4]int64{0, 1, 2, 4}[fhd&3] // Dictionary_ID
+	fcs := [4]int64{0, 2, 4, 8}[fhd>>6] // Frame_Content_Size
+	if fcs == 0 && single {
+		fcs = 1
+	}
+	size += fcs
+
+	for {
+		if _, err := r.ReadAt(buf[:3], offset+size); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, false, err
+		}
+		header := uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16
+		size += 3
+		switch header >> 1 & 3 {
+		case 1: // RLE_Block
+			size++
+		case 3: // Reserved
+			return 0, false, ErrInvalidZstdFrame
+		default:
+			size += int64(header >> 3)
+		}
+		if header&1 == 1 {
+			break
+		}
+	}
+	if fhd>>2&1 == 1 {
+		size += 4 // Content_Checksum
+	}
+	return size, false, nil
+}
@@ -0,0 +1,205 @@
+package oviewer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressTestLines returns the lines from start to end.
+func compressTestLines(start int, end int) []byte {
+	var b bytes.Buffer
+	for i := start; i < end; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.Bytes()
+}
+
+// writeCompressedTestFile writes the file compressed every member lines.
+func writeCompressedTestFile(t *testing.T, cFormat Compressed, lines int, member int) string {
+	t.Helper()
+	var b bytes.Buffer
+	for start := 0; start < lines; start += member {
+		data := compressTestLines(start, min(start+member, lines))
+		var w io.WriteCloser
+		var err error
+		switch cFormat {
+		case GZIP:
+			w = gzip.NewWriter(&b)
+		case ZSTD:
+			w, err = zstd.NewWriter(&b)
+		case XZ:
+			w, err = xz.NewWriter(&b)
+		default:
+			t.Fatalf("unsupported format %s", cFormat)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fileName := filepath.Join(t.TempDir(), "test."+cFormat.String())
+	if err := os.WriteFile(fileName, b.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func Test_compressedSeeker(t *testing.T) {
+	t.Parallel()
+	want := compressTestLines(0, 3000)
+	tests := []struct {
+		name       string
+		cFormat    Compressed
+		member     int
+		wantPoints int
+	}{
+		{
+			name:       "gzip members",
+			cFormat:    GZIP,
+			member:     1000,
+			wantPoints: 3,
+		},
+		{
+			name:       "gzip single",
+			cFormat:    GZIP,
+			member:     3000,
+			wantPoints: 1,
+		},
+		{
+			name:       "zstd frames",
+			cFormat:    ZSTD,
+			member:     500,
+			wantPoints: 6,
+		},
+		{
+			name:       "xz",
+			cFormat:    XZ,
+			member:     1000,
+			wantPoints: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fileName := writeCompressedTestFile(t, tt.cFormat, 3000, tt.member)
+			f, err := os.Open(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if got := fileCompressType(f, fileName); got != tt.cFormat {
+				t.Fatalf("fileCompressType() = %s, want %s", got, tt.cFormat)
+			}
+			if got := hasSeekPoints(f, tt.cFormat); got != (tt.wantPoints > 1) {
+				t.Errorf("hasSeekPoints() = %v, want %v", got, tt.wantPoints > 1)
+			}
+			s := newCompressedSeeker(f, tt.cFormat)
+			all, err := io.ReadAll(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(all, want) {
+				t.Fatalf("ReadAll() length %d, want %d", len(all), len(want))
+			}
+			if len(s.points) != tt.wantPoints {
+				t.Errorf("seek points = %d, want %d", len(s.points), tt.wantPoints)
+			}
+			// Seek backward beyond the history, forward and back within the history.
+			for _, offset := range []int64{10, int64(len(want)) - 100, 20000, 19990, 5} {
+				got, err := s.Seek(offset, io.SeekStart)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != offset {
+					t.Fatalf("Seek() = %d, want %d", got, offset)
+				}
+				buf := make([]byte, 50)
+				n, err := io.ReadFull(s, buf)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(buf[:n], want[offset:offset+int64(n)]) {
+					t.Errorf("Read() after Seek(%d) = %q, want %q", offset, buf[:n], want[offset:offset+int64(n)])
+				}
+			}
+			if _, err := s.Seek(0, io.SeekEnd); err == nil {
+				t.Errorf("Seek(io.SeekEnd) should return error")
+			}
+		})
+	}
+}
+
+func TestDocument_compressedSeek(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		cFormat      Compressed
+		member       int
+		wantSeekable bool
+	}{
+		{
+			name:         "gzip members",
+			cFormat:      GZIP,
+			member:       7000,
+			wantSeekable: true,
+		},
+		{
+			name:         "zstd frames",
+			cFormat:      ZSTD,
+			member:       4000,
+			wantSeekable: true,
+		},
+		{
+			name:         "gzip single",
+			cFormat:      GZIP,
+			member:       25000,
+			wantSeekable: false,
+		},
+		{
+			name:         "zstd single",
+			cFormat:      ZSTD,
+			member:       25000,
+			wantSeekable: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fileName := writeCompressedTestFile(t, tt.cFormat, 25000, tt.member)
+			m := openIndexTestDocument(t, fileName)
+			// Files without seek points are kept in memory as before.
+			if m.seekable != tt.wantSeekable {
+				t.Fatalf("seekable = %v, want %v", m.seekable, tt.wantSeekable)
+			}
+			if m.CFormat != tt.cFormat {
+				t.Errorf("CFormat = %s, want %s", m.CFormat, tt.cFormat)
+			}
+			if got := m.BufEndNum(); got != 25000 {
+				t.Fatalf("BufEndNum() = %d, want 25000", got)
+			}
+			// Chunks after the first one are only counted, so they are loaded by seeking.
+			// Otherwise, all chunks are in memory.
+			for _, lN := range []int{0, 24999, 10000, 19999, 12345} {
+				want := fmt.Sprintf("line %d", lN)
+				if got := indexTestLine(t, m, lN); got != want {
+					t.Errorf("line %d = %q, want %q", lN, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package oviewer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// compressTestData compresses the data in the format.
+func compressTestData(t *testing.T, cFormat Compressed, data []byte) []byte {
+	t.Helper()
+	if cFormat == LZIP {
+		return lzipTestData(t, data)
+	}
+	var b bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch cFormat {
+	case GZIP:
+		w = gzip.NewWriter(&b)
+	case ZSTD:
+		w, err = zstd.NewWriter(&b)
+	case BROTLI:
+		w = brotli.NewWriter(&b)
+	case ZLIB:
+		w = zlib.NewWriter(&b)
+	case SNAPPY:
+		w = snappy.NewBufferedWriter(&b)
+	default:
+		t.Fatalf("unsupported format %s", cFormat)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// lzipTestData returns an lzip member of the data.
+func lzipTestData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := lzma.WriterConfig{DictCap: 1 << 20, EOSMarker: true}.NewWriter(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	stream := b.Bytes()[lzma.HeaderLen:]
+
+	member := append([]byte{0x4c, 0x5a, 0x49, 0x50, 0x01, 20}, stream...)
+	trailer := make([]byte, lzipTrailerLen)
+	binary.LittleEndian.PutUint32(trailer[0:], crc32.ChecksumIEEE(data))
+	binary.LittleEndian.PutUint64(trailer[4:], uint64(len(data)))
+	binary.LittleEndian.PutUint64(trailer[12:], uint64(len(member)+lzipTrailerLen))
+	return append(member, trailer...)
+}
+
+func Test_compressType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		header []byte
+		want   Compressed
+	}{
+		{
+			name:   "gzip",
+			header: []byte{0x1f, 0x8b, 0x8, 0, 0, 0, 0},
+			want:   GZIP,
+		},
+		{
+			name:   "lzip",
+			header: []byte("LZIP\x01\x14\x00"),
+			want:   LZIP,
+		},
+		{
+			name:   "snappy",
+			header: []byte("\xff\x06\x00\x00sNaPpY"),
+			want:   SNAPPY,
+		},
+		{
+			name:   "zlib",
+			header: compressTestData(t, ZLIB, compressTestLines(0, 100)),
+			want:   ZLIB,
+		},
+		{
+			name:   "zlib truncated",
+			header: compressTestData(t, ZLIB, compressTestLines(0, 1000))[:zlibProbeSize],
+			want:   ZLIB,
+		},
+		{
+			name:   "text like zlib header",
+			header: []byte("x^2 + y^2 = z^2\n"),
+			want:   UNCOMPRESSED,
+		},
+		{
+			name:   "zlib with dictionary",
+			header: []byte{0x78, 0xbb, 0, 0, 0, 0, 0},
+			want:   UNCOMPRESSED,
+		},
+		{
+			name:   "text",
+			header: []byte("xyzzy\n\n"),
+			want:   UNCOMPRESSED,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := compressType(tt.header); got != tt.want {
+				t.Errorf("compressType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uncompressedReaderZlibText(t *testing.T) {
+	t.Parallel()
+	want := []byte("x^2 + y^2 = z^2\n" + string(compressTestLines(0, 100)))
+	cFormat, r := uncompressedReader(bytes.NewReader(want), "", false)
+	if cFormat != UNCOMPRESSED {
+		t.Fatalf("uncompressedReader() format = %v, want %v", cFormat, UNCOMPRESSED)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("uncompressedReader() = %q, want %q", got, want)
+	}
+}
+
+func Test_compressTypeByName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want Compressed
+	}{
+		{name: "access.log.br", want: BROTLI},
+		{name: "ACCESS.LOG.BR", want: BROTLI},
+		{name: "access.log", want: UNCOMPRESSED},
+		{name: "", want: UNCOMPRESSED},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := compressTypeByName(tt.name); got != tt.want {
+				t.Errorf("compressTypeByName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCompressed(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		want    Compressed
+		wantErr bool
+	}{
+		{name: "gzip", want: GZIP},
+		{name: "Brotli", want: BROTLI},
+		{name: "lz", want: LZIP},
+		{name: "none", want: UNCOMPRESSED},
+		{name: "rar", want: UNCOMPRESSED, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseCompressed(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCompressed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCompressed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uncompressedReader(t *testing.T) {
+	t.Parallel()
+	first := compressTestLines(0, 1000)
+	second := compressTestLines(1000, 2000)
+	want := append(append([]byte{}, first...), second...)
+	tests := []struct {
+		name     string
+		fileName string
+		cFormat  Compressed
+		members  int
+		padding  int
+	}{
+		{name: "gzip members", cFormat: GZIP, members: 2},
+		{name: "gzip padding", cFormat: GZIP, members: 2, padding: 512},
+		{name: "zstd frames", cFormat: ZSTD, members: 2},
+		{name: "zstd padding", cFormat: ZSTD, members: 2, padding: 512},
+		{name: "brotli", fileName: "test.br", cFormat: BROTLI, members: 1},
+		{name: "zlib", cFormat: ZLIB, members: 1},
+		{name: "lzip", cFormat: LZIP, members: 1},
+		{name: "lzip members", cFormat: LZIP, members: 2},
+		{name: "snappy", cFormat: SNAPPY, members: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var data []byte
+			if tt.members == 1 {
+				data = compressTestData(t, tt.cFormat, want)
+			} else {
+				data = append(compressTestData(t, tt.cFormat, first), compressTestData(t, tt.cFormat, second)...)
+			}
+			data = append(data, make([]byte, tt.padding)...)
+
+			cFormat, r := uncompressedReader(bytes.NewReader(data), tt.fileName, false)
+			if cFormat != tt.cFormat {
+				t.Fatalf("uncompressedReader() format = %v, want %v", cFormat, tt.cFormat)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("uncompressedReader() length = %d, want %d", len(got), len(want))
+			}
+		})
+	}
+}
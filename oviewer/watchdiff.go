@@ -0,0 +1,165 @@
+package oviewer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// watchSnapshot is the content of one refresh in watch mode.
+// The snapshots are separated by FormFeed lines in the store.
+type watchSnapshot struct {
+	// start is the first line number of the snapshot.
+	start int
+	// time is the time of the refresh.
+	time time.Time
+}
+
+// addSnapshot records the start of the snapshot after the FormFeed.
+// The first content is recorded as the first snapshot,
+// and the oldest snapshots are dropped from the history over WatchHistory.
+// The chunks that contain only the dropped snapshots are also freed.
+func (s *store) addSnapshot() {
+	s.mu.Lock()
+	if len(s.snapshots) == 0 {
+		s.snapshots = append(s.snapshots, watchSnapshot{start: 0})
+	}
+	s.snapshots = append(s.snapshots, watchSnapshot{start: int(s.endNum), time: time.Now()})
+	if WatchHistory <= 0 || len(s.snapshots) <= WatchHistory {
+		s.mu.Unlock()
+		return
+	}
+	s.snapshots = append([]watchSnapshot(nil), s.snapshots[len(s.snapshots)-WatchHistory:]...)
+	oldest := s.snapshots[0].start
+	s.mu.Unlock()
+	s.dropChunksBefore(oldest)
+}
+
+// dropChunksBefore frees the chunks before the chunk that contains the line
+// and moves the start position to the beginning of the chunk.
+// The first chunk is kept, because it is always regarded as loaded.
+func (s *store) dropChunksBefore(lN int) {
+	chunkNum, _ := chunkLineNum(lN)
+	if chunkNum == 0 {
+		return
+	}
+	for _, n := range s.loadedChunks.Keys() {
+		if n > 0 && n < chunkNum {
+			s.unloadChunk(n)
+		}
+	}
+	if start := int32(chunkNum * ChunkSize); atomic.LoadInt32(&s.startNum) < start {
+		atomic.StoreInt32(&s.startNum, start)
+	}
+}
+
+// snapshotList returns a copy of the snapshots.
+func (s *store) snapshotList() []watchSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]watchSnapshot(nil), s.snapshots...)
+}
+
+// snapshotIndex returns the index of the snapshot that contains the line.
+// It returns -1 if the line is before the history.
+func snapshotIndex(snapshots []watchSnapshot, lN int) int {
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].start <= lN {
+			return i
+		}
+	}
+	return -1
+}
+
+// previousSnapshotLine returns the line number of the same position in the previous snapshot.
+// It returns -1 if the previous snapshot does not have the line (the line is added),
+// and false if there is nothing to compare.
+func previousSnapshotLine(snapshots []watchSnapshot, lN int) (int, bool) {
+	i := snapshotIndex(snapshots, lN)
+	if i <= 0 {
+		return 0, false
+	}
+	// The FormFeed line that separates the next snapshot.
+	if i+1 < len(snapshots) && lN == snapshots[i+1].start-1 {
+		return 0, false
+	}
+	prevLN := snapshots[i-1].start + (lN - snapshots[i].start)
+	// The last line of the previous snapshot is the FormFeed.
+	if prevLN >= snapshots[i].start-1 {
+		return -1, true
+	}
+	return prevLN, true
+}
+
+// watchDiffHighlight applies the style to the characters
+// that have changed from the same line of the previous snapshot.
+func (root *Root) watchDiffHighlight(lN int, line LineC) {
+	m := root.Doc
+	prevLN, ok := previousSnapshotLine(m.store.snapshotList(), lN)
+	if !ok {
+		return
+	}
+	if prevLN < 0 {
+		RangeStyle(line.lc, 0, len(line.lc), root.StyleWatchDiff)
+		return
+	}
+	prev, valid := m.getLineC(prevLN, m.TabWidth)
+	if !valid {
+		return
+	}
+	for x := 0; x < len(line.lc); x++ {
+		if x < len(prev.lc) && line.lc[x].mainc == prev.lc[x].mainc && line.lc[x].width == prev.lc[x].width {
+			continue
+		}
+		line.lc[x].style = applyStyle(line.lc[x].style, root.StyleWatchDiff)
+	}
+}
+
+// toggleWatchDiff toggles the highlight of the changes in watch mode.
+func (root *Root) toggleWatchDiff() {
+	root.Doc.WatchDiff = !root.Doc.WatchDiff
+	root.setMessagef("Set WatchDiff %t", root.Doc.WatchDiff)
+}
+
+// watchPrevious moves to the previous snapshot in watch mode.
+// The display stops following the latest snapshot until it returns to the latest.
+func (root *Root) watchPrevious() {
+	root.moveSnapshot(-1)
+}
+
+// watchNext moves to the next snapshot in watch mode.
+func (root *Root) watchNext() {
+	root.moveSnapshot(1)
+}
+
+// moveSnapshot moves to the snapshot relative to the current snapshot.
+func (root *Root) moveSnapshot(n int) {
+	m := root.Doc
+	if !m.WatchMode {
+		root.setMessage("not in watch mode")
+		return
+	}
+	snapshots := m.store.snapshotList()
+	if len(snapshots) < 2 {
+		root.setMessage("no snapshot history")
+		return
+	}
+	current := snapshotIndex(snapshots, m.topLN+m.firstLine())
+	target := current + n
+	if target < 0 || target >= len(snapshots) {
+		root.setMessagef("no more snapshots (history %d)", len(snapshots))
+		return
+	}
+
+	latest := target == len(snapshots)-1
+	m.FollowSection = latest
+	m.moveLine(snapshots[target].start - m.firstLine())
+	t := "first"
+	if !snapshots[target].time.IsZero() {
+		t = snapshots[target].time.Format("15:04:05")
+	}
+	if latest {
+		root.setMessagef("snapshot %d/%d %s (latest)", target+1, len(snapshots), t)
+		return
+	}
+	root.setMessagef("snapshot %d/%d %s", target+1, len(snapshots), t)
+}
@@ -0,0 +1,99 @@
+package oviewer
+
+import (
+	"testing"
+)
+
+func Test_snapshotIndex(t *testing.T) {
+	t.Parallel()
+	snapshots := []watchSnapshot{{start: 5}, {start: 10}, {start: 15}}
+	tests := []struct {
+		lN   int
+		want int
+	}{
+		{lN: 0, want: -1},
+		{lN: 5, want: 0},
+		{lN: 9, want: 0},
+		{lN: 10, want: 1},
+		{lN: 20, want: 2},
+	}
+	for _, tt := range tests {
+		if got := snapshotIndex(snapshots, tt.lN); got != tt.want {
+			t.Errorf("snapshotIndex(%d) = %d, want %d", tt.lN, got, tt.want)
+		}
+	}
+}
+
+func Test_previousSnapshotLine(t *testing.T) {
+	t.Parallel()
+	// 0-2: first content, 3: FF, 4-7: second content, 8: FF, 9-: third content.
+	snapshots := []watchSnapshot{{start: 0}, {start: 4}, {start: 9}}
+	tests := []struct {
+		name   string
+		lN     int
+		want   int
+		wantOK bool
+	}{
+		{name: "first snapshot", lN: 1, want: 0, wantOK: false},
+		{name: "same position", lN: 5, want: 1, wantOK: true},
+		{name: "added line", lN: 7, want: -1, wantOK: true},
+		{name: "formfeed", lN: 8, want: 0, wantOK: false},
+		{name: "latest", lN: 9, want: 4, wantOK: true},
+		{name: "latest added line", lN: 13, want: -1, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := previousSnapshotLine(snapshots, tt.lN)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: previousSnapshotLine(%d) = %d, %v, want %d, %v", tt.name, tt.lN, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestStore_addSnapshot(t *testing.T) {
+	defer func(history int) { WatchHistory = history }(WatchHistory)
+	WatchHistory = 3
+	s := NewStore()
+	chunk := s.chunks[0]
+	for i := 0; i < 5; i++ {
+		s.appendLine(chunk, []byte("line\n"))
+		s.appendFormFeed(chunk)
+	}
+	got := s.snapshotList()
+	if len(got) != 3 {
+		t.Fatalf("len(snapshots) = %d, want 3", len(got))
+	}
+	for i, want := range []int{6, 8, 10} {
+		if got[i].start != want {
+			t.Errorf("snapshots[%d].start = %d, want %d", i, got[i].start, want)
+		}
+	}
+}
+
+func TestStore_addSnapshotDrop(t *testing.T) {
+	defer func(history int) { WatchHistory = history }(WatchHistory)
+	WatchHistory = 2
+	s := NewStore()
+	s.setNewLoadChunks(100)
+	// Each snapshot has the lines of one chunk and a FormFeed.
+	for i := 0; i < 4; i++ {
+		for n := 0; n < ChunkSize-1; n++ {
+			s.appendLine(s.chunkForAdd(false, s.size), []byte("line\n"))
+		}
+		s.appendFormFeed(s.chunkForAdd(false, s.size))
+	}
+	got := s.snapshotList()
+	if len(got) != 2 || got[0].start != ChunkSize*3 {
+		t.Fatalf("snapshots = %v, want 2 snapshots from %d", got, ChunkSize*3)
+	}
+	if start := int(s.startNum); start != ChunkSize*3 {
+		t.Errorf("startNum = %d, want %d", start, ChunkSize*3)
+	}
+	for chunkNum := 1; chunkNum < 3; chunkNum++ {
+		if len(s.chunks[chunkNum].lines) != 0 {
+			t.Errorf("chunk %d is not freed", chunkNum)
+		}
+	}
+	if len(s.chunks[3].lines) == 0 {
+		t.Errorf("chunk 3 of the kept snapshot is freed")
+	}
+}